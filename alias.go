@@ -0,0 +1,78 @@
+package alice
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const _AliceAliasesMethodName = "AliceAliases"
+
+// Alias is another name of an instance. Like the instance name, it is qualified by the namespace of the module.
+type Alias struct {
+	Name string
+	// Deprecated makes the container log a warning whenever the alias is used.
+	Deprecated bool
+}
+
+// AliasedModule is implemented by modules which provide instances by other names as well, e.g. the old names of
+// renamed providers. Aliases resolve the same as instance names in tags and InstanceByName, so renames could be rolled
+// out gradually.
+//
+//	func (m *ExampleModule) AliceAliases() map[string][]alice.Alias {
+//		return map[string][]alice.Alias{
+//			"HTTPClient": {{Name: "Client", Deprecated: true}},
+//		}
+//	}
+type AliasedModule interface {
+	Module
+	// AliceAliases returns the aliases of instances, keyed by the provider method names.
+	AliceAliases() map[string][]Alias
+}
+
+// WithLogf sets the function logging warnings, such as the use of deprecated aliases. It defaults to log.Printf. It is
+// not used if a logger is set by WithLogger.
+func WithLogf(logf func(format string, args ...interface{})) Option {
+	return optionFunc(func(o *options) {
+		o.logf = logf
+	})
+}
+
+// warnDeprecated logs a warning of the use of a deprecated alias, which is a dependency name or an instance name. It
+// logs at warn level if a logger is set by WithLogger, or by the function set by WithLogf otherwise.
+func (o *options) warnDeprecated(kind string, name string, target string) {
+	if o.logger != nil {
+		o.logger.Warn("alice: "+kind+" is a deprecated alias", "name", name, "use", target)
+		return
+	}
+	o.logf("alice: %s %s is a deprecated alias, use %s instead", kind, name, target)
+}
+
+// applyAliases sets the aliases of the instances if the module implements AliasedModule. It returns error if a
+// provider is not defined, or an alias is not a valid instance name.
+func applyAliases(m Module, instances []*instanceMethod) error {
+	am, ok := m.(AliasedModule)
+	if !ok || !declaresHook(m, _AliceAliasesMethodName) {
+		return nil
+	}
+
+	name := reflect.TypeOf(m).Elem().Name()
+	for method, aliases := range am.AliceAliases() {
+		instance := findInstance(instances, method)
+		if instance == nil {
+			return fmt.Errorf("aliased provider %s.%s is not defined", name, method)
+		}
+		for _, alias := range aliases {
+			if !isInstanceName(alias.Name) {
+				return fmt.Errorf("alias %q of %s.%s is not a valid instance name", alias.Name, name, method)
+			}
+		}
+		instance.aliases = append(instance.aliases, aliases...)
+	}
+	return nil
+}
+
+// aliasTarget is the instance an alias refers to.
+type aliasTarget struct {
+	name       string
+	deprecated bool
+}
@@ -0,0 +1,115 @@
+package alice
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type RenamedModule struct {
+	BaseModule
+}
+
+func (m *RenamedModule) AliceAliases() map[string][]Alias {
+	return map[string][]Alias{
+		"D1": {{Name: "OldD1", Deprecated: true}, {Name: "FirstDep"}},
+	}
+}
+
+func (m *RenamedModule) D1() D1 {
+	return &D1Impl{}
+}
+
+type RenamedServerModule struct {
+	BaseModule
+	*RenamedModule
+}
+
+func (m *RenamedServerModule) Address() string {
+	return ":8080"
+}
+
+type OldNameModule struct {
+	BaseModule
+	D1 D1 `alice:"OldD1"`
+}
+
+type undefinedAliasModule struct {
+	BaseModule
+}
+
+func (m *undefinedAliasModule) AliceAliases() map[string][]Alias {
+	return map[string][]Alias{
+		"D1": {{Name: "OldD1"}},
+	}
+}
+
+type DuplicatedAliasModule struct {
+	BaseModule
+}
+
+func (m *DuplicatedAliasModule) AliceAliases() map[string][]Alias {
+	return map[string][]Alias{
+		"D2": {{Name: "OldD1"}},
+	}
+}
+
+func (m *DuplicatedAliasModule) D2() D2 {
+	return &D2Impl{}
+}
+
+func TestAlias(t *testing.T) {
+	var warnings []string
+	logf := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+	oldName := &OldNameModule{}
+	c, err := NewContainerWithOptions([]Module{&RenamedModule{}, oldName}, WithLogf(logf))
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(oldName.D1, &D1Impl{}) {
+		t.Errorf("bad field assigned by alias: got %v, expected %v", oldName.D1, &D1Impl{})
+	}
+	if d1 := c.InstanceByName("FirstDep"); d1 != oldName.D1 {
+		t.Errorf("bad instance after InstanceByName() by alias: got %v, expected %v", d1, oldName.D1)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "OldNameModule.OldD1 is a deprecated alias, use D1") {
+		t.Errorf("bad warnings after NewContainer(): got %v, expected a warning of OldNameModule.OldD1", warnings)
+	}
+
+	c.InstanceByName("OldD1")
+	if len(warnings) != 2 || !strings.Contains(warnings[1], "OldD1 is a deprecated alias, use D1") {
+		t.Errorf("bad warnings after InstanceByName() by deprecated alias: got %v", warnings)
+	}
+	c.Instance(reflect.TypeOf((*D1)(nil)).Elem())
+}
+
+func TestAlias_EmbeddedModule(t *testing.T) {
+	c, err := NewContainer(&RenamedServerModule{RenamedModule: &RenamedModule{}})
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if d1 := c.InstanceByName("FirstDep"); !reflect.DeepEqual(d1, &D1Impl{}) {
+		t.Errorf("bad instance after InstanceByName() by alias: got %v, expected %v", d1, &D1Impl{})
+	}
+}
+
+func TestAlias_UndefinedProvider(t *testing.T) {
+	_, err := reflectModule(&undefinedAliasModule{})
+	if err == nil {
+		t.Error("expect error after reflectModule() on alias of undefined provider")
+	}
+	t.Log(err.Error())
+}
+
+func TestAlias_Duplicated(t *testing.T) {
+	_, err := NewContainer(&RenamedModule{}, &DuplicatedAliasModule{})
+	if err == nil {
+		t.Error("expect error after NewContainer() on duplicated alias")
+	}
+	t.Log(err.Error())
+}
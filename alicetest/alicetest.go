@@ -0,0 +1,204 @@
+// Package alicetest provides helpers for testing alice modules.
+//
+// A module is usually tested in isolation by providing stubs for its tagged fields:
+//
+//	h := alicetest.New(t, &module.BusinessModule{}, map[string]interface{}{
+//		"WebPageDao": fakeDao,
+//		"HTTPClient": fakeClient,
+//	})
+//	manager := alicetest.Instance[*business.WebPageManager](h)
+//
+// The whole module set of an application could be validated without calling any provider:
+//
+//	alicetest.AssertGraph(t, &module.ConfigModule{}, &module.PersistModule{}, ...)
+package alicetest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/magic003/alice"
+)
+
+const _Tag = "alice"
+
+var _ModuleType = reflect.TypeOf((*alice.Module)(nil)).Elem()
+
+// _StubNamePrefix prefixes the names of stubs for fields associated by type, so that they don't collide with the
+// instances provided by the module under test.
+const _StubNamePrefix = "alicetest:"
+
+// Harness is a container built with a single module and stubs for its dependencies.
+type Harness struct {
+	t testing.TB
+	c alice.Container
+}
+
+// New builds a container with the module under test and stubs for its tagged fields. The stubs are keyed by field
+// name. It fails the test if a tagged field has no stub, a stub doesn't match any tagged field, or the container
+// cannot be built. Options are passed to the container as is.
+func New(t testing.TB, m alice.Module, stubs map[string]interface{}, opts ...alice.Option) *Harness {
+	t.Helper()
+
+	stubOpts, err := stubOptions(m, stubs)
+	if err != nil {
+		t.Fatalf("alicetest: %s", err.Error())
+	}
+
+	c, err := alice.NewContainerWithOptions([]alice.Module{m}, append(stubOpts, opts...)...)
+	if err != nil {
+		t.Fatalf("alicetest: failed to build container for module %s: %s", moduleName(m), err.Error())
+	}
+	return &Harness{
+		t: t,
+		c: c,
+	}
+}
+
+// Container returns the container built by the harness.
+func (h *Harness) Container() alice.Container {
+	return h.c
+}
+
+// Instance returns the instance of type T. It fails the test if no instance or multiple instances are found.
+func Instance[T any](h *Harness) T {
+	h.t.Helper()
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	var instance interface{}
+	if err := catch(func() { instance = h.c.Instance(t) }); err != nil {
+		h.t.Fatalf("alicetest: failed to get instance of type %s: %s", t, err.Error())
+	}
+	return instance.(T)
+}
+
+// InstanceByName returns the instance with the specified name. It fails the test if no instance is found, or the
+// instance is not of type T.
+func InstanceByName[T any](h *Harness, name string) T {
+	h.t.Helper()
+
+	var instance interface{}
+	if err := catch(func() { instance = h.c.InstanceByName(name) }); err != nil {
+		h.t.Fatalf("alicetest: failed to get instance %s: %s", name, err.Error())
+	}
+	typed, ok := instance.(T)
+	if !ok {
+		h.t.Fatalf("alicetest: instance %s is of type %T, not %s",
+			name, instance, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return typed
+}
+
+// AssertGraph validates the modules the same way as building a container, without calling any provider method. It
+// fails the test if any of the module is invalid, a dependency is not satisfied, or there are cyclic dependencies.
+func AssertGraph(t testing.TB, modules ...alice.Module) {
+	t.Helper()
+
+	if err := alice.Validate(modules...); err != nil {
+		t.Fatalf("alicetest: invalid module graph: %s", err.Error())
+	}
+}
+
+// stubOptions creates options which add the stubs as instances for the tagged fields of the module, including the
+// fields of the modules embedded in it, so a composed module is tested as a whole.
+func stubOptions(m alice.Module, stubs map[string]interface{}) ([]alice.Option, error) {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("module %s is not a pointer of struct", v.String())
+	}
+
+	fields := taggedFields(v.Elem(), make(map[reflect.Type]bool))
+	used := make(map[string]bool)
+	added := make(map[string]bool)
+	var opts []alice.Option
+	for _, f := range fields {
+		stub, ok := stubs[f.field.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing stub for field %s.%s `alice:%q` of type %s",
+				f.module, f.field.Name, f.dependName, f.field.Type)
+		}
+		used[f.field.Name] = true
+
+		// fields associated by type share a stub per type, otherwise the type would be ambiguous.
+		name := f.dependName
+		if name == "" {
+			name = _StubNamePrefix + f.field.Type.String()
+		}
+		// fields of embedded modules may depend on the same instance.
+		if added[name] {
+			continue
+		}
+		added[name] = true
+		opts = append(opts, alice.WithInstance(name, stub))
+	}
+
+	var unused []string
+	for name := range stubs {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return nil, fmt.Errorf("stubs %v don't match any tagged field of module %s", unused, moduleName(m))
+	}
+
+	return opts, nil
+}
+
+// taggedField is a field with the alice tag, and the name of the module declaring it.
+type taggedField struct {
+	module     string
+	field      reflect.StructField
+	dependName string
+}
+
+// taggedFields returns the tagged fields of the module struct and of the modules embedded in it recursively. visited
+// avoids visiting a module type twice when it is embedded in multiple modules.
+func taggedFields(v reflect.Value, visited map[reflect.Type]bool) []*taggedField {
+	t := v.Type()
+	visited[t] = true
+	var fields []*taggedField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && !visited[fv.Type()] && fv.Addr().Type().Implements(_ModuleType) {
+				fields = append(fields, taggedFields(fv, visited)...)
+			}
+			continue
+		}
+		if dependName, exists := field.Tag.Lookup(_Tag); exists {
+			fields = append(fields, &taggedField{module: t.Name(), field: field, dependName: dependName})
+		}
+	}
+	return fields
+}
+
+// moduleName returns the name of the module type.
+func moduleName(m alice.Module) string {
+	t := reflect.TypeOf(m)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// catch calls the function and converts a panic to error.
+func catch(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	f()
+	return nil
+}
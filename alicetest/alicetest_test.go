@@ -0,0 +1,158 @@
+package alicetest
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/magic003/alice"
+	"github.com/magic003/alice/example/business"
+	"github.com/magic003/alice/example/client"
+	"github.com/magic003/alice/example/module"
+	"github.com/magic003/alice/example/persist"
+)
+
+type fakeHTTPClient struct{}
+
+func (client *fakeHTTPClient) Fetch(url string) string {
+	return ""
+}
+
+type fakeWebPageDao struct{}
+
+func (dao *fakeWebPageDao) Find(url string) string {
+	return ""
+}
+
+func (dao *fakeWebPageDao) Save(url string, content string) error {
+	return nil
+}
+
+// recordingT records the fatal message and stops the calling goroutine the same as testing.T.
+type recordingT struct {
+	testing.TB
+	failed  bool
+	message string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.failed = true
+	t.message = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// run calls the function with a recordingT in a separate goroutine, and returns the recordingT.
+func run(f func(t *recordingT)) *recordingT {
+	t := &recordingT{}
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		f(t)
+	}()
+	<-done
+	return t
+}
+
+func TestNew(t *testing.T) {
+	h := New(t, &module.BusinessModule{}, map[string]interface{}{
+		"WebPageDao": &fakeWebPageDao{},
+		"HTTPClient": &fakeHTTPClient{},
+	})
+
+	manager := Instance[*business.WebPageManager](h)
+	if manager == nil {
+		t.Error("bad instance from Instance(): got nil")
+	}
+
+	manager = InstanceByName[*business.WebPageManager](h, "WebPageManager")
+	if manager == nil {
+		t.Error("bad instance from InstanceByName(): got nil")
+	}
+}
+
+func TestNew_MissingStub(t *testing.T) {
+	rt := run(func(rt *recordingT) {
+		New(rt, &module.BusinessModule{}, map[string]interface{}{
+			"WebPageDao": &fakeWebPageDao{},
+		})
+	})
+
+	if !rt.failed {
+		t.Error("expected test failure after New() on missing stub")
+	}
+	t.Log(rt.message)
+}
+
+func TestNew_UnusedStub(t *testing.T) {
+	rt := run(func(rt *recordingT) {
+		New(rt, &module.PersistModule{}, map[string]interface{}{
+			"Table":   "table",
+			"Retries": 3,
+		})
+	})
+
+	if !rt.failed {
+		t.Error("expected test failure after New() on unused stub")
+	}
+	t.Log(rt.message)
+}
+
+type ServerModule struct {
+	alice.BaseModule
+	module.PersistModule
+	*module.ClientModule
+}
+
+func TestNew_EmbeddedModules(t *testing.T) {
+	h := New(t, &ServerModule{ClientModule: &module.ClientModule{}}, map[string]interface{}{
+		"Table":   "table",
+		"Retries": 3,
+	})
+
+	if dao := InstanceByName[persist.WebPageDao](h, "WebPageDao"); dao == nil {
+		t.Error("bad instance of embedded module from InstanceByName(): got nil")
+	}
+	if httpClient := InstanceByName[client.HTTPClient](h, "HTTPClient"); httpClient == nil {
+		t.Error("bad instance of embedded module from InstanceByName(): got nil")
+	}
+
+	rt := run(func(rt *recordingT) {
+		New(rt, &ServerModule{ClientModule: &module.ClientModule{}}, map[string]interface{}{
+			"Retries": 3,
+		})
+	})
+	if !rt.failed || !strings.Contains(rt.message, "PersistModule.Table") {
+		t.Errorf("bad failure after New() on missing stub of embedded module: got %q", rt.message)
+	}
+}
+
+func TestInstanceByName_WrongType(t *testing.T) {
+	h := New(t, &module.PersistModule{}, map[string]interface{}{
+		"Table": "table",
+	})
+
+	rt := run(func(rt *recordingT) {
+		h.t = rt
+		InstanceByName[string](h, "WebPageDao")
+	})
+
+	if !rt.failed {
+		t.Error("expected test failure after InstanceByName() on wrong type")
+	}
+	t.Log(rt.message)
+}
+
+func TestAssertGraph(t *testing.T) {
+	AssertGraph(t, &module.ConfigModule{}, &module.PersistModule{}, &module.ClientModule{}, &module.BusinessModule{})
+
+	rt := run(func(rt *recordingT) {
+		AssertGraph(rt, &module.PersistModule{}, &module.ClientModule{}, &module.BusinessModule{})
+	})
+	if !rt.failed {
+		t.Error("expected test failure after AssertGraph() on missing module")
+	}
+	t.Log(rt.message)
+}
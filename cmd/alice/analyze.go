@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// analysis is the result of analyzing the modules in packages.
+type analysis struct {
+	loader  *loader
+	pkgs    []*loadedPackage
+	modules []*staticModule
+	graph   *staticGraph
+	order   []*staticModule
+}
+
+// analyze loads the packages matching the patterns with the loader, and analyzes the modules active in the profiles.
+// The returned error lists all the errors found, with source positions.
+func analyze(l *loader, patterns []string, profiles []string) (*analysis, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	pkgs, err := l.load(patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	modules, errs := analyzeModules(l, pkgs, newProfileSet(profiles))
+	if len(errs) > 0 {
+		return nil, l.formatErrors(errs...)
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("alice: no module found in %s", strings.Join(patterns, " "))
+	}
+	applyConditions(modules)
+	applyFallbacks(modules)
+
+	g, err := createStaticGraph(modules...)
+	if err != nil {
+		return nil, l.formatErrors(err)
+	}
+	order, err := g.instantiationOrder()
+	if err != nil {
+		return nil, l.formatErrors(err)
+	}
+
+	return &analysis{
+		loader:  l,
+		pkgs:    pkgs,
+		modules: modules,
+		graph:   g,
+		order:   order,
+	}, nil
+}
+
+// formatErrors combines the errors into one, prefixing each with its source position if known.
+func (l *loader) formatErrors(errs ...error) error {
+	var lines []string
+	for _, err := range errs {
+		if perr, ok := err.(*positionedError); ok && perr.pos != token.NoPos {
+			lines = append(lines, fmt.Sprintf("%s: %s", l.fset.Position(perr.pos), perr.msg))
+		} else {
+			lines = append(lines, err.Error())
+		}
+	}
+	return errors.New(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+)
+
+// staticCondition is the static counterpart of alice.Condition. kind is the name of the function creating it, e.g.
+// OnMissingType.
+type staticCondition struct {
+	kind string
+	tp   types.Type
+	name string
+	pos  token.Pos
+}
+
+// applyProviderConditions sets the conditions of the instances if the module declares the AliceConditions method in
+// the package. The method is evaluated statically, so it must return a map literal whose keys are constant strings and
+// whose values are slice literals of conditions. A condition on a type must be created from reflect.TypeOf, e.g.
+// alice.OnMissingType(reflect.TypeOf((*Client)(nil)).Elem()), and a condition on a name from a constant string. It
+// returns error if the method could not be evaluated, or a provider is not defined.
+func applyProviderConditions(pkg *loadedPackage, named *types.Named, instances []*staticInstance) error {
+	sel := lookupHook(named, _AliceConditionsMethodName)
+	if sel == nil {
+		return nil
+	}
+
+	name := named.Obj().Name()
+	invalid := errorf(sel.Obj().Pos(), "method %s.%s could not be evaluated statically; return a map literal of "+
+		"conditions on reflect.TypeOf types or constant names", name, _AliceConditionsMethodName)
+	lit, ok := returnedExpr(pkg, sel.Obj()).(*ast.CompositeLit)
+	if !ok {
+		return invalid
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return invalid
+		}
+		key := pkg.info.Types[kv.Key].Value
+		values, ok := kv.Value.(*ast.CompositeLit)
+		if key == nil || key.Kind() != constant.String || !ok {
+			return invalid
+		}
+		method := constant.StringVal(key)
+		instance := findStaticInstance(instances, method)
+		if instance == nil {
+			return errorf(kv.Key.Pos(), "conditional provider %s.%s is not defined", name, method)
+		}
+		for _, value := range values.Elts {
+			c, ok := evalCondition(pkg, value)
+			if !ok {
+				return invalid
+			}
+			instance.conditions = append(instance.conditions, c)
+		}
+	}
+	return nil
+}
+
+// evalCondition evaluates a call of the functions creating alice.Condition.
+func evalCondition(pkg *loadedPackage, expr ast.Expr) (*staticCondition, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil, false
+	}
+	fn, ok := calledFunc(pkg, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != _AlicePackagePath {
+		return nil, false
+	}
+
+	c := &staticCondition{kind: fn.Name(), pos: call.Pos()}
+	switch c.kind {
+	case "OnMissingType", "OnPresentType":
+		c.tp, ok = evalReflectType(pkg, call.Args[0])
+		return c, ok
+	case "OnMissingName", "OnPresentName":
+		value := pkg.info.Types[call.Args[0]].Value
+		if value == nil || value.Kind() != constant.String || constant.StringVal(value) == "" {
+			return nil, false
+		}
+		c.name = constant.StringVal(value)
+		return c, true
+	}
+	return nil, false
+}
+
+// evalReflectType evaluates an expression of reflect.Type, which is either reflect.TypeOf(x) of a non-interface x, or
+// the Elem method called on such an expression.
+func evalReflectType(pkg *loadedPackage, expr ast.Expr) (types.Type, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Elem" && len(call.Args) == 0 {
+		t, ok := evalReflectType(pkg, sel.X)
+		if !ok {
+			return nil, false
+		}
+		if elem, ok := t.Underlying().(interface{ Elem() types.Type }); ok {
+			return elem.Elem(), true
+		}
+		return nil, false
+	}
+
+	fn, ok := calledFunc(pkg, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "reflect" || fn.Name() != "TypeOf" || len(call.Args) != 1 {
+		return nil, false
+	}
+	t := pkg.info.TypeOf(call.Args[0])
+	if t == nil || types.IsInterface(t) {
+		return nil, false
+	}
+	return t, true
+}
+
+// calledFunc returns the object of the function called, or nil if it is not a named function.
+func calledFunc(pkg *loadedPackage, call *ast.CallExpr) types.Object {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return pkg.info.Uses[fun]
+	case *ast.SelectorExpr:
+		return pkg.info.Uses[fun.Sel]
+	}
+	return nil
+}
+
+// holds checks if the condition holds against the instances provided by the modules, the same as package alice.
+func (c *staticCondition) holds(provided []*staticProvidedInstance) bool {
+	present := false
+	for _, p := range provided {
+		switch c.kind {
+		case "OnMissingType", "OnPresentType":
+			present = types.AssignableTo(p.instance.tp, c.tp)
+		default:
+			present = p.hasName(c.name)
+		}
+		if present {
+			break
+		}
+	}
+	if c.kind == "OnMissingType" || c.kind == "OnMissingName" {
+		return !present
+	}
+	return present
+}
+
+// staticProvidedInstance is an instance and the module providing it.
+type staticProvidedInstance struct {
+	m        *staticModule
+	instance *staticInstance
+}
+
+// hasName checks if the instance has the name, qualified or not, or an alias of the name.
+func (p *staticProvidedInstance) hasName(name string) bool {
+	if name == p.m.qualifiedName(p.instance.name) || name == p.instance.name {
+		return true
+	}
+	for _, alias := range p.instance.aliases {
+		if name == p.m.qualifiedName(alias.name) || name == alias.name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasQualifiedName checks if the qualified name of the instance, or of an alias, is the name.
+func (p *staticProvidedInstance) hasQualifiedName(name string) bool {
+	if name == p.m.qualifiedName(p.instance.name) {
+		return true
+	}
+	for _, alias := range p.instance.aliases {
+		if name == p.m.qualifiedName(alias.name) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyConditions removes the conditional instances whose conditions don't hold, the same as package alice.
+func applyConditions(modules []*staticModule) {
+	var provided []*staticProvidedInstance
+	for _, m := range modules {
+		for _, instance := range m.instances {
+			if len(instance.conditions) == 0 {
+				provided = append(provided, &staticProvidedInstance{m: m, instance: instance})
+			}
+		}
+	}
+
+	for _, m := range modules {
+		var instances []*staticInstance
+		for _, instance := range m.instances {
+			if len(instance.conditions) == 0 {
+				instances = append(instances, instance)
+				continue
+			}
+			if conditionsHold(instance.conditions, provided) {
+				instances = append(instances, instance)
+				provided = append(provided, &staticProvidedInstance{m: m, instance: instance})
+			}
+		}
+		m.instances = instances
+	}
+}
+
+// conditionsHold checks if all of the conditions hold against the provided instances.
+func conditionsHold(conditions []*staticCondition, provided []*staticProvidedInstance) bool {
+	for _, c := range conditions {
+		if !c.holds(provided) {
+			return false
+		}
+	}
+	return true
+}
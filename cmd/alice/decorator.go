@@ -0,0 +1,104 @@
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+// staticDecorator is a method decorating the instance with the target name. It is the static counterpart of the
+// decoratorMethod in package alice.
+type staticDecorator struct {
+	m      *staticModule
+	name   string
+	target string
+	tp     types.Type
+	pos    token.Pos
+}
+
+// moduleDecorators returns the decorators sorted by method name, if the module declares the AliceDecorators method in
+// the package. The method is evaluated statically, so it must return a map literal of constant strings. It returns
+// error if the method could not be evaluated, or a decorator is not defined or doesn't take and return the same type.
+func moduleDecorators(pkg *loadedPackage, named *types.Named) ([]*staticDecorator, error) {
+	mset := types.NewMethodSet(types.NewPointer(named))
+	sel := lookupHook(named, _AliceDecoratorsMethodName)
+	if sel == nil {
+		return nil, nil
+	}
+
+	name := named.Obj().Name()
+	invalid := errorf(sel.Obj().Pos(), "method %s.%s could not be evaluated statically; return a map literal of "+
+		"constant strings", name, _AliceDecoratorsMethodName)
+	lit, ok := returnedExpr(pkg, sel.Obj()).(*ast.CompositeLit)
+	if !ok {
+		return nil, invalid
+	}
+	var decorators []*staticDecorator
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil, invalid
+		}
+		key := pkg.info.Types[kv.Key].Value
+		value := pkg.info.Types[kv.Value].Value
+		if key == nil || key.Kind() != constant.String || value == nil || value.Kind() != constant.String {
+			return nil, invalid
+		}
+		method := constant.StringVal(key)
+		msel := mset.Lookup(named.Obj().Pkg(), method)
+		if msel == nil {
+			return nil, errorf(kv.Key.Pos(), "decorator %s.%s is not defined", name, method)
+		}
+		sig := msel.Obj().Type().(*types.Signature)
+		if sig.Params().Len() != 1 || sig.Results().Len() != 1 ||
+			!types.Identical(sig.Params().At(0).Type(), sig.Results().At(0).Type()) {
+			return nil, errorf(msel.Obj().Pos(), "decorator %s.%s doesn't take and return the same type",
+				name, method)
+		}
+		decorators = append(decorators, &staticDecorator{
+			name:   method,
+			target: constant.StringVal(value),
+			tp:     sig.Results().At(0).Type(),
+			pos:    kv.Pos(),
+		})
+	}
+	sort.Slice(decorators, func(i, j int) bool {
+		return decorators[i].name < decorators[j].name
+	})
+	return decorators, nil
+}
+
+// isDecorator checks if the method is one of the decorators.
+func isDecorator(decorators []*staticDecorator, method string) bool {
+	for _, d := range decorators {
+		if d.name == method {
+			return true
+		}
+	}
+	return false
+}
+
+// createDecorations attaches the decorators of a module to the instances they decorate, the same as package alice.
+func (g *staticGraph) createDecorations(m *staticModule, names *staticNameIndex) error {
+	for _, d := range m.decorators {
+		provider, instance, candidates := names.lookup(d.target)
+		if len(candidates) > 1 {
+			return errorf(d.pos, "decorated name %s of %s.%s is ambiguous, use one of the qualified names %v",
+				d.target, m.name, d.name, candidates)
+		}
+		if provider == nil {
+			return errorf(d.pos, "decorated name %s of %s.%s is not found", d.target, m.name, d.name)
+		}
+		if !types.Identical(instance.tp, d.tp) {
+			return errorf(d.pos, "decorator %s.%s of type %s doesn't match %s.%s of type %s",
+				m.name, d.name, typeString(d.tp), provider.name, instance.name, typeString(instance.tp))
+		}
+		instance.decorators = append(instance.decorators, d)
+		if provider != m {
+			g.addDependencyEdge(m, provider)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"go/types"
+)
+
+// markFallbacks flags the fallback instances if the module declares the AliceFallbacks method in the package. The
+// method is evaluated statically, so it must return a slice literal of constant strings. It returns error if the
+// method could not be evaluated, or a provider is not defined.
+func markFallbacks(pkg *loadedPackage, named *types.Named, instances []*staticInstance) error {
+	sel := lookupHook(named, _AliceFallbacksMethodName)
+	if sel == nil {
+		return nil
+	}
+
+	name := named.Obj().Name()
+	methods, ok := evalStrings(pkg, returnedExpr(pkg, sel.Obj()))
+	if !ok {
+		return errorf(sel.Obj().Pos(), "method %s.%s could not be evaluated statically; return a slice literal of "+
+			"constant strings", name, _AliceFallbacksMethodName)
+	}
+	for _, method := range methods {
+		instance := findStaticInstance(instances, method)
+		if instance == nil {
+			return errorf(sel.Obj().Pos(), "fallback provider %s.%s is not defined", name, method)
+		}
+		instance.fallback = true
+	}
+	return nil
+}
+
+// applyFallbacks removes the fallback instances which are replaced by other instances with the same qualified name,
+// the same as package alice.
+func applyFallbacks(modules []*staticModule) {
+	var provided []*staticProvidedInstance
+	for _, m := range modules {
+		for _, instance := range m.instances {
+			if !instance.fallback {
+				provided = append(provided, &staticProvidedInstance{m: m, instance: instance})
+			}
+		}
+	}
+
+	for _, m := range modules {
+		var instances []*staticInstance
+		for _, instance := range m.instances {
+			if !instance.fallback {
+				instances = append(instances, instance)
+				continue
+			}
+			if !isReplaced(m, instance, provided) {
+				instances = append(instances, instance)
+				provided = append(provided, &staticProvidedInstance{m: m, instance: instance})
+			}
+		}
+		m.instances = instances
+	}
+}
+
+// isReplaced checks if any of the provided instances has the same qualified name as the fallback instance of the
+// module, the same as package alice.
+func isReplaced(m *staticModule, fallback *staticInstance, provided []*staticProvidedInstance) bool {
+	name := m.qualifiedName(fallback.name)
+	for _, p := range provided {
+		if p.hasQualifiedName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// findFallback finds the first fallback instance of the identical type, or the first one assignable to the type if
+// none is of the identical type. It returns nil if not found.
+func findFallback(fallbacks []*staticProvidedInstance, t types.Type) *staticProvidedInstance {
+	var assignable *staticProvidedInstance
+	for _, p := range fallbacks {
+		if types.Identical(p.instance.tp, t) {
+			return p
+		}
+		if assignable == nil && types.AssignableTo(p.instance.tp, t) {
+			assignable = p
+		}
+	}
+	return assignable
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	_DefaultOutput        = "alice_gen.go"
+	_DefaultContainerType = "AliceContainer"
+	_StaticContainerName  = "StaticContainer"
+)
+
+// runGenerate generates a Go file which wires the modules in the packages with plain Go code. The file is placed in
+// the first package. It is usually run by go:generate:
+//
+//	//go:generate alice generate .
+func runGenerate(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	output := flags.String("o", _DefaultOutput, "output file, relative to the directory of the first package")
+	typeName := flags.String("type", _DefaultContainerType, "name of the generated container type")
+	var profiles profilesFlag
+	flags.Var(&profiles, "profiles", "comma separated active profiles")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	l := newLoader()
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	// the output file is replaced, so it doesn't take part in the analysis.
+	bpkgs, err := l.resolve(patterns[0])
+	if err != nil {
+		return err
+	}
+	if len(bpkgs) == 0 {
+		return fmt.Errorf("alice: no package found in %s", patterns[0])
+	}
+	filename := *output
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(bpkgs[0].Dir, filename)
+	}
+	if err := l.ignore(filename); err != nil {
+		return err
+	}
+
+	a, err := analyze(l, patterns, profiles)
+	if err != nil {
+		return err
+	}
+	src, err := generate(a, a.pkgs[0], *typeName)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, src, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "alice: generated %s\n", filename)
+	return nil
+}
+
+// generator generates the source code wiring the modules in a package.
+type generator struct {
+	a        *analysis
+	pkg      *types.Package
+	typeName string
+	// imports maps package paths to the names used in the generated code.
+	imports map[string]string
+	buf     bytes.Buffer
+}
+
+// generate generates the source code of the container wiring the modules. The code is in the package pkg.
+func generate(a *analysis, pkg *loadedPackage, typeName string) ([]byte, error) {
+	g := &generator{
+		a:        a,
+		pkg:      pkg.types,
+		typeName: typeName,
+		imports:  make(map[string]string),
+	}
+	if err := g.check(); err != nil {
+		return nil, a.loader.formatErrors(err)
+	}
+
+	g.body()
+	body := append([]byte(nil), g.buf.Bytes()...)
+	g.buf.Reset()
+	g.printf("// Code generated by alice generate. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", g.pkg.Name())
+	g.printf("import (\n")
+	paths := g.sortedImports()
+	for i, path := range paths {
+		if i > 0 && !isStd(path) && isStd(paths[i-1]) {
+			g.printf("\n")
+		}
+		name := g.imports[path]
+		if name == filepath.Base(path) {
+			g.printf("%s\n", strconv.Quote(path))
+		} else {
+			g.printf("%s %s\n", name, strconv.Quote(path))
+		}
+	}
+	g.printf(")\n\n")
+	g.buf.Write(body)
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("alice: failed to format generated code: %s", err.Error())
+	}
+	return src, nil
+}
+
+// check checks the modules could be wired by code in the package.
+func (g *generator) check() error {
+	reserved := g.reservedNames()
+	fields := make(map[string]string)
+	for _, m := range g.a.modules {
+		for _, instance := range m.instances {
+			field := fieldName(m, instance)
+			if reserved[field] {
+				return errorf(instance.pos, "instance name %s conflicts with a method or field of %s",
+					field, g.typeName)
+			}
+			if other, ok := fields[field]; ok {
+				return errorf(instance.pos, "instances %s and %s have the same field name %s in %s",
+					other, m.qualifiedName(instance.name), field, g.typeName)
+			}
+			fields[field] = m.qualifiedName(instance.name)
+		}
+		if m.tp.Obj().Pkg() == g.pkg {
+			continue
+		}
+		if !m.tp.Obj().Exported() {
+			return errorf(m.pos, "module %s is not exported from package %s", m.name, m.tp.Obj().Pkg().Path())
+		}
+		for _, edge := range g.a.graph.edges {
+			if edge.dependant == m && !token.IsExported(edge.fieldName) {
+				return errorf(m.pos, "field %s.%s is not exported from package %s",
+					m.name, edge.fieldName, m.tp.Obj().Pkg().Path())
+			}
+		}
+	}
+	return nil
+}
+
+// reservedNames returns the names which could not be used as instance names, because they are used by the
+// generated container type.
+func (g *generator) reservedNames() map[string]bool {
+	reserved := map[string]bool{_StaticContainerName: true}
+	if pkg, ok := g.a.loader.packages[_AlicePackagePath]; ok {
+		static := pkg.types.Scope().Lookup(_StaticContainerName).Type()
+		mset := types.NewMethodSet(types.NewPointer(static))
+		for i := 0; i < mset.Len(); i++ {
+			reserved[mset.At(i).Obj().Name()] = true
+		}
+	}
+	return reserved
+}
+
+// body generates the code after the imports.
+func (g *generator) body() {
+	alice := g.importName(_AlicePackagePath)
+	reflect := g.importName("reflect")
+	// record all the imports before naming the parameters, so they don't conflict.
+	for _, m := range g.a.modules {
+		g.typeString(m.tp)
+		for _, instance := range m.instances {
+			g.typeString(instance.tp)
+		}
+	}
+	withContext := g.anyInstance(func(instance *staticInstance) bool { return instance.withContext })
+	withError := g.anyInstance(func(instance *staticInstance) bool { return instance.withError })
+	var contextType string
+	if withContext {
+		contextType = g.importName("context") + ".Context"
+	}
+	params := g.paramNames(withContext, withError)
+
+	g.printf("// %s contains the instances wired by %s. It implements %s.Container.\n",
+		g.typeName, "New"+g.typeName, alice)
+	g.printf("type %s struct {\n", g.typeName)
+	g.printf("*%s.%s\n\n", alice, _StaticContainerName)
+	for _, m := range g.a.modules {
+		for _, instance := range m.instances {
+			g.printf("%s %s\n", fieldName(m, instance), g.typeString(instance.tp))
+		}
+	}
+	g.printf("}\n\n")
+	g.printf("var _ %s.Container = (*%s)(nil)\n\n", alice, g.typeName)
+
+	g.printf("// New%s wires the modules in instantiation order and returns the instances.\n", g.typeName)
+	if withContext {
+		g.printf("// Providers taking a context are called with ctx.\n")
+	}
+	if withError {
+		g.printf("// It returns *%s.ProviderError if a provider fails. Retry policies are not applied.\n", alice)
+	}
+	g.printf("func New%s(\n", g.typeName)
+	if withContext {
+		g.printf("ctx %s,\n", contextType)
+	}
+	for _, m := range g.a.modules {
+		if m.embedding == nil {
+			g.printf("%s %s,\n", params[m], g.typeString(types.NewPointer(m.tp)))
+		}
+	}
+	if withError {
+		g.printf(") (*%s, error) {\n", g.typeName)
+	} else {
+		g.printf(") *%s {\n", g.typeName)
+	}
+	// embedded modules are taken from the modules embedding them.
+	declared := make(map[*staticModule]bool)
+	var declare func(m *staticModule)
+	declare = func(m *staticModule) {
+		if m.embedding == nil || declared[m] {
+			return
+		}
+		declared[m] = true
+		declare(m.embedding.parent)
+		if m.embedding.pointer {
+			g.printf("%s := %s.%s\n", params[m], params[m.embedding.parent], m.embedding.fieldName)
+		} else {
+			g.printf("%s := &%s.%s\n", params[m], params[m.embedding.parent], m.embedding.fieldName)
+		}
+	}
+	for _, m := range g.a.modules {
+		declare(m)
+	}
+	g.printf("c := &%s{%s: %s.New%s()}\n", g.typeName, _StaticContainerName, alice, _StaticContainerName)
+	if withError {
+		g.printf("var err error\n")
+	}
+	for _, m := range g.a.order {
+		g.printf("\n// %s\n", m.name)
+		for _, edge := range g.a.graph.edges {
+			if edge.dependant != m {
+				continue
+			}
+			value := "c." + fieldName(edge.provider, edge.instance)
+			if edge.asserted != nil {
+				value += ".(" + g.typeString(edge.asserted) + ")"
+			}
+			g.printf("%s.%s = %s\n", params[m], edge.fieldName, value)
+		}
+		for _, instance := range m.instances {
+			field := fieldName(m, instance)
+			arg := ""
+			if instance.withContext {
+				arg = "ctx"
+			}
+			if instance.withError {
+				g.printf("if c.%s, err = %s.%s(%s); err != nil {\n", field, params[m], instance.method, arg)
+				g.printf("return nil, &%s.ProviderError{Module: %s, Method: %s, Instance: %s, Attempts: 1, "+
+					"Err: err}\n", alice, strconv.Quote(m.name), strconv.Quote(instance.method),
+					strconv.Quote(m.qualifiedName(instance.name)))
+				g.printf("}\n")
+			} else {
+				g.printf("c.%s = %s.%s(%s)\n", field, params[m], instance.method, arg)
+			}
+			for _, d := range instance.decorators {
+				g.printf("c.%s = %s.%s(c.%s)\n", field, params[d.m], d.name, field)
+			}
+			if !instance.fallback {
+				g.addInstance(m, instance, "Add", reflect)
+			}
+		}
+	}
+	// fallbacks are added in the order of modules and providers, in which they are found by type.
+	commented := false
+	for _, m := range g.a.modules {
+		for _, instance := range m.instances {
+			if !instance.fallback {
+				continue
+			}
+			if !commented {
+				g.printf("\n// fallbacks\n")
+				commented = true
+			}
+			g.addInstance(m, instance, "AddFallback", reflect)
+		}
+	}
+	if withError {
+		g.printf("\nreturn c, nil\n")
+	} else {
+		g.printf("\nreturn c\n")
+	}
+	g.printf("}\n")
+}
+
+// addInstance prints the statements adding the instance and its aliases to the container by the method, Add or
+// AddFallback.
+func (g *generator) addInstance(m *staticModule, instance *staticInstance, method string, reflect string) {
+	g.printf("c.%s(%s, %s.TypeOf((*%s)(nil)).Elem(), c.%s)\n", method, strconv.Quote(m.qualifiedName(instance.name)),
+		reflect, g.typeString(instance.tp), fieldName(m, instance))
+	for _, alias := range instance.aliases {
+		g.printf("c.AddAlias(%s, %s, %v)\n", strconv.Quote(m.qualifiedName(alias.name)),
+			strconv.Quote(m.qualifiedName(instance.name)), alias.deprecated)
+	}
+}
+
+// anyInstance checks if any instance matches the predicate, e.g. taking a context, so the generated function takes
+// one.
+func (g *generator) anyInstance(predicate func(instance *staticInstance) bool) bool {
+	for _, m := range g.a.modules {
+		for _, instance := range m.instances {
+			if predicate(instance) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// paramNames returns the parameter names of the modules, which don't conflict with imports or other identifiers. The
+// name ctx is reserved if the generated function takes a context, and err if it returns error.
+func (g *generator) paramNames(withContext bool, withError bool) map[*staticModule]string {
+	used := map[string]bool{"c": true, "ctx": withContext, "err": withError}
+	for _, name := range g.imports {
+		used[name] = true
+	}
+	names := make(map[*staticModule]string)
+	for _, m := range g.a.modules {
+		base := lowerFirst(m.name)
+		name := base
+		for i := 2; used[name] || token.IsKeyword(name) || g.pkg.Scope().Lookup(name) != nil; i++ {
+			name = base + strconv.Itoa(i)
+		}
+		used[name] = true
+		names[m] = name
+	}
+	return names
+}
+
+// typeString returns the string of the type in the generated code, and records the imports needed.
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		if pkg == g.pkg {
+			return ""
+		}
+		return g.importName(pkg.Path())
+	})
+}
+
+// importName returns the name of an imported package, and records the import. A package whose name conflicts with
+// another import gets a numbered name.
+func (g *generator) importName(path string) string {
+	if name, ok := g.imports[path]; ok {
+		return name
+	}
+	base := filepath.Base(path)
+	if pkg, ok := g.a.loader.packages[path]; ok {
+		base = pkg.types.Name()
+	}
+	name := base
+	for i := 2; g.importNameUsed(name); i++ {
+		name = base + strconv.Itoa(i)
+	}
+	g.imports[path] = name
+	return name
+}
+
+// importNameUsed checks if the name is used by another import or the package scope.
+func (g *generator) importNameUsed(name string) bool {
+	for _, n := range g.imports {
+		if n == name {
+			return true
+		}
+	}
+	return g.pkg.Scope().Lookup(name) != nil
+}
+
+// sortedImports returns the import paths, standard library first.
+func (g *generator) sortedImports() []string {
+	var paths []string
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if isStd(paths[i]) != isStd(paths[j]) {
+			return isStd(paths[i])
+		}
+		return paths[i] < paths[j]
+	})
+	return paths
+}
+
+// isStd checks if the import path is of the standard library.
+func isStd(path string) bool {
+	return !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// fieldName returns the name of the field of an instance in the generated container type. An instance in a namespace
+// is prefixed by the namespace, e.g. PaymentsClient for payments.Client. The field is named after the provider method,
+// since the instance name might not be an identifier.
+func fieldName(m *staticModule, instance *staticInstance) string {
+	if m.namespace == "" {
+		return instance.method
+	}
+	r := []rune(m.namespace)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r) + instance.method
+}
+
+// lowerFirst lowers the first letter of the name.
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	expected, err := os.ReadFile(filepath.Join(_ExamplePackage, _DefaultOutput))
+	if err != nil {
+		t.Fatalf("unexpected error reading generated file: %s", err.Error())
+	}
+
+	l := newLoader()
+	if err := l.ignore(filepath.Join(_ExamplePackage, _DefaultOutput)); err != nil {
+		t.Fatalf("unexpected error after ignore(): %s", err.Error())
+	}
+	a, err := analyze(l, []string{_ExamplePackage}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error after analyze(): %s", err.Error())
+	}
+	src, err := generate(a, a.pkgs[0], _DefaultContainerType)
+	if err != nil {
+		t.Fatalf("unexpected error after generate(): %s", err.Error())
+	}
+
+	if !bytes.Equal(src, expected) {
+		t.Errorf("generated code is different from %s, run go generate: got\n%s", _DefaultOutput, src)
+	}
+}
+
+func TestRun_Generate(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "container.go")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"generate", "-o", output, "-type", "Wiring", _ExamplePackage}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("bad exit code after generate: got %d, expected 0, stderr: %s", code, stderr.String())
+	}
+
+	src, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("unexpected error reading generated file: %s", err.Error())
+	}
+	if !strings.Contains(string(src), "func NewWiring(") {
+		t.Errorf("bad generated code: got\n%s\nexpected to contain func NewWiring(", src)
+	}
+}
+
+func TestRun_GenerateReservedName(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "container.go")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"generate", "-o", output, "./testdata/reserved"}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("bad exit code after generate: got %d, expected 1", code)
+	}
+	expected := "instance name Add conflicts with a method or field of AliceContainer"
+	if !strings.Contains(stderr.String(), expected) {
+		t.Errorf("bad error after generate: got %q, expected to contain %q", stderr.String(), expected)
+	}
+}
+
+func TestGenerate_EmbeddedModules(t *testing.T) {
+	a, err := analyze(newLoader(), []string{"./testdata/compose"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error after analyze(): %s", err.Error())
+	}
+	src, err := generate(a, a.pkgs[0], _DefaultContainerType)
+	if err != nil {
+		t.Fatalf("unexpected error after generate(): %s", err.Error())
+	}
+
+	for _, expected := range []string{
+		"func NewAliceContainer(\n\tserverModule *ServerModule,\n) *AliceContainer {\n",
+		"loggingModule := &serverModule.LoggingModule\n",
+		"configModule := serverModule.ConfigModule\n",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("bad generated code: got\n%s\nexpected to contain %q", src, expected)
+		}
+	}
+}
+
+func TestGenerate_NamespacesAliasesAndNames(t *testing.T) {
+	a, err := analyze(newLoader(), []string{"./testdata/namespace"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error after analyze(): %s", err.Error())
+	}
+	src, err := generate(a, a.pkgs[0], _DefaultContainerType)
+	if err != nil {
+		t.Fatalf("unexpected error after generate(): %s", err.Error())
+	}
+
+	for _, expected := range []string{
+		"c.PaymentsClient = paymentsModule.Client()\n",
+		"c.Add(\"payments.Client\", reflect.TypeOf((*Client)(nil)).Elem(), c.PaymentsClient)\n",
+		"checkoutModule.Shipping = c.ShippingClient\n",
+		"c.AddAlias(\"payments.Gateway\", \"payments.Client\", true)\n",
+		"c.Client = trackingModule.Client()\n",
+		"c.Add(\"tracking-client\", reflect.TypeOf((*Client)(nil)).Elem(), c.Client)\n",
+		"checkoutModule.Tracking = c.Client\n",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("bad generated code: got\n%s\nexpected to contain %q", src, expected)
+		}
+	}
+}
+
+func TestGenerate_Decorators(t *testing.T) {
+	a, err := analyze(newLoader(), []string{"./testdata/decorate"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error after analyze(): %s", err.Error())
+	}
+	src, err := generate(a, a.pkgs[0], _DefaultContainerType)
+	if err != nil {
+		t.Fatalf("unexpected error after generate(): %s", err.Error())
+	}
+
+	expected := "c.Client = clientModule.Client()\n" +
+		"\tc.Client = retryModule.WithRetries(c.Client)\n" +
+		"\tc.Client = metricsModule.WithMetrics(c.Client)\n"
+	if !strings.Contains(string(src), expected) {
+		t.Errorf("bad generated code: got\n%s\nexpected to contain %q", src, expected)
+	}
+}
+
+func TestGenerate_Fallbacks(t *testing.T) {
+	a, err := analyze(newLoader(), []string{"./testdata/fallback"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error after analyze(): %s", err.Error())
+	}
+	src, err := generate(a, a.pkgs[0], _DefaultContainerType)
+	if err != nil {
+		t.Fatalf("unexpected error after generate(): %s", err.Error())
+	}
+
+	for _, expected := range []string{
+		"serviceModule.Logger = c.TeamLogger\n",
+		"serviceModule.Timeout = c.Timeout\n",
+		"c.AddFallback(\"Logger\", reflect.TypeOf((*Logger)(nil)).Elem(), c.Logger)\n",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("bad generated code: got\n%s\nexpected to contain %q", src, expected)
+		}
+	}
+}
+
+func TestGenerate_Context(t *testing.T) {
+	a, err := analyze(newLoader(), []string{"./testdata/contextual"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error after analyze(): %s", err.Error())
+	}
+	src, err := generate(a, a.pkgs[0], _DefaultContainerType)
+	if err != nil {
+		t.Fatalf("unexpected error after generate(): %s", err.Error())
+	}
+
+	for _, expected := range []string{
+		"func NewAliceContainer(\n\tctx context.Context,\n\tpersistModule *PersistModule,\n",
+		"c.DB = persistModule.DB(ctx)\n",
+		"c.DSN = persistModule.DSN()\n",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("bad generated code: got\n%s\nexpected to contain %q", src, expected)
+		}
+	}
+}
+
+func TestGenerate_ErrorProviders(t *testing.T) {
+	a, err := analyze(newLoader(), []string{"./testdata/retry"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error after analyze(): %s", err.Error())
+	}
+	src, err := generate(a, a.pkgs[0], _DefaultContainerType)
+	if err != nil {
+		t.Fatalf("unexpected error after generate(): %s", err.Error())
+	}
+
+	for _, expected := range []string{
+		") (*AliceContainer, error) {\n",
+		"\tif c.Broker, err = messagingModule.Broker(); err != nil {\n" +
+			"\t\treturn nil, &alice.ProviderError{Module: \"MessagingModule\", Method: \"Broker\", Instance: \"Broker\", " +
+			"Attempts: 1, Err: err}\n",
+		"\treturn c, nil\n",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("bad generated code: got\n%s\nexpected to contain %q", src, expected)
+		}
+	}
+}
+
+func TestGenerate_InterfaceToConcreteField(t *testing.T) {
+	a, err := analyze(newLoader(), []string{"./testdata/narrow"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error after analyze(): %s", err.Error())
+	}
+	src, err := generate(a, a.pkgs[0], _DefaultContainerType)
+	if err != nil {
+		t.Fatalf("unexpected error after generate(): %s", err.Error())
+	}
+
+	expected := "crawlerModule.Client = c.Client.(*HTTPClient)\n"
+	if !strings.Contains(string(src), expected) {
+		t.Errorf("bad generated code: got\n%s\nexpected to contain %q", src, expected)
+	}
+}
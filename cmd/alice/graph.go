@@ -0,0 +1,405 @@
+package main
+
+import (
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/magic003/alice"
+)
+
+// staticGraph maintains the dependency relationship of the modules. It is the static counterpart of the graph in
+// package alice, and follows the same rules. Dependants are kept in slices, so the instantiation order is
+// deterministic.
+type staticGraph struct {
+	modules []*staticModule
+	// g is map representing the dependency graph. Modules in value depend on the key.
+	g     map[*staticModule][]*staticModule
+	edges []*staticEdge
+}
+
+// staticEdge is the dependency from a field of a module to the instance assigned to it.
+type staticEdge struct {
+	provider  *staticModule
+	instance  *staticInstance
+	dependant *staticModule
+	fieldName string
+	named     bool
+	// asserted is set if the instance is of an interface type, which is asserted to the concrete type of the field.
+	asserted types.Type
+}
+
+// typeProviders is the modules providing instances of a type.
+type typeProviders struct {
+	tp        types.Type
+	providers []*staticModule
+}
+
+// createStaticGraph creates a graph of modules. It returns error if a dependency is not satisfied.
+func createStaticGraph(modules ...*staticModule) (*staticGraph, error) {
+	g := &staticGraph{
+		modules: modules,
+		g:       make(map[*staticModule][]*staticModule),
+	}
+	if err := g.constructGraph(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// constructGraph constructs a graph based on the dependency of the modules.
+func (g *staticGraph) constructGraph() error {
+	names, typeToProviders, fallbacks, err := g.computeProviders()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range g.modules {
+		if err := g.createDependenciesByNames(m, names); err != nil {
+			return err
+		}
+		if err := g.createDependenciesByTypes(m, typeToProviders, fallbacks); err != nil {
+			return err
+		}
+		if err := g.createDecorations(m, names); err != nil {
+			return err
+		}
+		// overriding providers may call the overridden ones of the base modules.
+		for _, base := range m.bases {
+			g.addDependencyEdge(base, m)
+		}
+	}
+	return nil
+}
+
+// computeProviders figures out instance names and types, and the corresponding modules that provide them. Fallback
+// instances are returned separately in the order of modules and providers.
+func (g *staticGraph) computeProviders() (*staticNameIndex, []*typeProviders, []*staticProvidedInstance, error) {
+	names := &staticNameIndex{
+		qualified:   make(map[string]*staticModule),
+		unqualified: make(map[string][]string),
+		instances:   make(map[string]*staticInstance),
+	}
+	var typeToProviders []*typeProviders
+	var fallbacks []*staticProvidedInstance
+
+	for _, provider := range g.modules {
+		for _, instance := range provider.instances {
+			if err := names.add(provider, instance); err != nil {
+				return nil, nil, nil, err
+			}
+			if instance.fallback {
+				fallbacks = append(fallbacks, &staticProvidedInstance{m: provider, instance: instance})
+				continue
+			}
+
+			if tps := findTypeProviders(typeToProviders, instance.tp); tps != nil {
+				tps.providers = append(tps.providers, provider)
+			} else {
+				typeToProviders = append(typeToProviders, &typeProviders{
+					tp:        instance.tp,
+					providers: []*staticModule{provider},
+				})
+			}
+		}
+	}
+
+	return names, typeToProviders, fallbacks, nil
+}
+
+// staticNameIndex finds the instances by qualified names, or by unqualified names if they are unambiguous. It is the
+// static counterpart of the nameIndex in package alice.
+type staticNameIndex struct {
+	qualified   map[string]*staticModule
+	unqualified map[string][]string
+	instances   map[string]*staticInstance
+}
+
+// add adds an instance of a module and its aliases. It returns error if a qualified name is provided by another
+// module.
+func (idx *staticNameIndex) add(m *staticModule, instance *staticInstance) error {
+	if err := idx.addName(m, instance, instance.name, instance.pos); err != nil {
+		return err
+	}
+	for _, alias := range instance.aliases {
+		if err := idx.addName(m, instance, alias.name, alias.pos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addName adds a name of an instance, which is qualified by the namespace of the module.
+func (idx *staticNameIndex) addName(m *staticModule, instance *staticInstance, short string, pos token.Pos) error {
+	name := m.qualifiedName(short)
+	if existing, ok := idx.qualified[name]; ok {
+		return errorf(pos, "duplicated name %s in module %s and %s; declare different namespaces with %s() "+
+			"and use qualified names, e.g. %s.%s and %s.%s", name, existing.name, m.name, _AliceNamespaceMethodName,
+			suggestedNamespace(existing), short, suggestedNamespace(m), short)
+	}
+	idx.qualified[name] = m
+	idx.instances[name] = instance
+	if m.namespace != "" {
+		idx.unqualified[short] = append(idx.unqualified[short], name)
+	}
+	return nil
+}
+
+// lookup finds the module and the instance by the name. If the name is unqualified and ambiguous, it returns the
+// qualified names as candidates.
+func (idx *staticNameIndex) lookup(name string) (*staticModule, *staticInstance, []string) {
+	if m, ok := idx.qualified[name]; ok {
+		return m, idx.instances[name], nil
+	}
+	candidates := idx.unqualified[name]
+	if len(candidates) == 1 {
+		return idx.qualified[candidates[0]], idx.instances[candidates[0]], nil
+	}
+	return nil, nil, candidates
+}
+
+// suggestedNamespace returns a namespace for the module derived from its name, e.g. "payments" for PaymentsModule.
+func suggestedNamespace(m *staticModule) string {
+	name := strings.TrimSuffix(m.name, "Module")
+	if name == "" {
+		name = m.name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// findTypeProviders finds the providers of the identical type, or nil if not found.
+func findTypeProviders(typeToProviders []*typeProviders, t types.Type) *typeProviders {
+	for _, tps := range typeToProviders {
+		if types.Identical(tps.tp, t) {
+			return tps
+		}
+	}
+	return nil
+}
+
+// createDependenciesByNames creates dependencies of a module using its named dependencies.
+func (g *staticGraph) createDependenciesByNames(m *staticModule, names *staticNameIndex) error {
+	for _, depField := range m.namedDepends {
+		depName := depField.name
+		provider, instance, candidates := names.lookup(depName)
+		if len(candidates) > 1 {
+			return errorf(depField.pos, "dependency name %s.%s is ambiguous, use one of the qualified names %v",
+				m.name, depName, candidates)
+		}
+		if provider == nil {
+			return errorf(depField.pos, "dependency name %s.%s is not found", m.name, depName)
+		}
+		if !assignableDependency(instance.tp, depField.tp) {
+			return errorf(depField.pos, "dependency name %s.%s of type %s is not assignable from %s.%s of type %s",
+				m.name, depName, typeString(depField.tp), provider.name, instance.name, typeString(instance.tp))
+		}
+		edge := &staticEdge{
+			provider:  provider,
+			instance:  instance,
+			dependant: m,
+			fieldName: depField.fieldName,
+			named:     true,
+		}
+		if !types.AssignableTo(instance.tp, depField.tp) {
+			edge.asserted = depField.tp
+		}
+		g.addDependencyEdge(provider, m)
+		g.edges = append(g.edges, edge)
+	}
+	return nil
+}
+
+// assignableDependency checks if an instance of type t could be assigned to a field of fieldType, the same as package
+// alice. An instance of an interface type is accepted for a field of a concrete type implementing the interface.
+func assignableDependency(t types.Type, fieldType types.Type) bool {
+	if types.AssignableTo(t, fieldType) {
+		return true
+	}
+	iface, ok := t.Underlying().(*types.Interface)
+	return ok && !types.IsInterface(fieldType) && types.Implements(fieldType, iface)
+}
+
+// createDependenciesByTypes creates dependencies of a module using its typed dependencies. A dependency is resolved to
+// a fallback instance only if no other instance matches its type.
+func (g *staticGraph) createDependenciesByTypes(
+	m *staticModule, typeToProviders []*typeProviders, fallbacks []*staticProvidedInstance) error {
+	for _, depField := range m.typedDepends {
+		depType := depField.tp
+		var providers []*staticModule
+		if tps := findTypeProviders(typeToProviders, depType); tps != nil {
+			providers = tps.providers
+		} else { // no identical type match, find assignable types
+			var assignableType types.Type
+			for _, tps := range typeToProviders {
+				if !types.AssignableTo(tps.tp, depType) {
+					continue
+				}
+				if assignableType != nil {
+					return errorf(depField.pos, "multiple assignable types %s, %s for type %s.%s",
+						typeString(tps.tp), typeString(assignableType), m.name, typeString(depType))
+				}
+				providers = tps.providers
+				assignableType = tps.tp
+			}
+		}
+		var instance *staticInstance
+		if len(providers) == 0 {
+			if fallback := findFallback(fallbacks, depType); fallback != nil {
+				providers = []*staticModule{fallback.m}
+				instance = fallback.instance
+			}
+		}
+
+		if len(providers) == 0 {
+			return errorf(depField.pos, "dependency type %s.%s is not found", m.name, typeString(depType))
+		}
+		if len(providers) > 1 {
+			var names []string
+			for _, p := range providers {
+				names = append(names, p.name)
+			}
+			return errorf(depField.pos, "dependency type %s.%s is found in mutiple modules: %s",
+				m.name, typeString(depType), names)
+		}
+		if instance == nil {
+			instance = providers[0].instanceOfType(depType)
+		}
+		g.addDependencyEdge(providers[0], m)
+		g.edges = append(g.edges, &staticEdge{
+			provider:  providers[0],
+			instance:  instance,
+			dependant: m,
+			fieldName: depField.fieldName,
+		})
+	}
+	return nil
+}
+
+// addDependencyEdge creates a dependency edge in the graph. dependant depends on parent.
+func (g *staticGraph) addDependencyEdge(parent *staticModule, dependant *staticModule) {
+	if g.hasDependant(parent, dependant) {
+		return
+	}
+	g.g[parent] = append(g.g[parent], dependant)
+}
+
+// hasDependant checks if dependant depends on parent.
+func (g *staticGraph) hasDependant(parent *staticModule, dependant *staticModule) bool {
+	for _, m := range g.g[parent] {
+		if m == dependant {
+			return true
+		}
+	}
+	return false
+}
+
+// instantiationOrder returns the instantiation order of the modules. It returns error if there is cyclic
+// dependencies.
+func (g *staticGraph) instantiationOrder() ([]*staticModule, error) {
+	visited := make(map[*staticModule]bool)
+	recVisited := make(map[*staticModule]bool)
+	var stack []*staticModule
+	var recPath []string
+
+	var dfs func(m *staticModule) error
+	dfs = func(m *staticModule) error {
+		recPath = append(recPath, m.name)
+		if recVisited[m] { // cyclic
+			return errorf(m.pos, "cyclic dependencies for modules: %s", strings.Join(recPath, " -> "))
+		}
+
+		recVisited[m] = true
+		// dependants are visited in the order of modules, the same as package alice.
+		for _, dependant := range g.modules {
+			if g.hasDependant(m, dependant) && !visited[dependant] {
+				if err := dfs(dependant); err != nil {
+					return err
+				}
+			}
+		}
+
+		visited[m] = true
+		stack = append(stack, m)
+		recVisited[m] = false
+		recPath = recPath[:len(recPath)-1]
+		return nil
+	}
+
+	for _, m := range g.modules {
+		if !visited[m] {
+			if err := dfs(m); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+		stack[i], stack[j] = stack[j], stack[i]
+	}
+	return stack, nil
+}
+
+// describe creates the description of the graph, the same as alice.DescribeGraph.
+func (g *staticGraph) describe(order []*staticModule) *alice.GraphDescription {
+	d := &alice.GraphDescription{
+		Version: alice.GraphSchemaVersion,
+		Modules: []*alice.ModuleDescription{},
+		Edges:   []*alice.EdgeDescription{},
+		Order:   []string{},
+	}
+	for _, m := range g.modules {
+		md := &alice.ModuleDescription{
+			ID:        m.id(),
+			Name:      m.name,
+			Package:   m.tp.Obj().Pkg().Path(),
+			Namespace: m.namespace,
+			Instances: []*alice.InstanceDescription{},
+			Fields:    []*alice.FieldDescription{},
+		}
+		for _, instance := range m.instances {
+			md.Instances = append(md.Instances, &alice.InstanceDescription{
+				Name: m.qualifiedName(instance.name),
+				Type: typeString(instance.tp),
+			})
+		}
+		for _, dep := range m.namedDepends {
+			md.Fields = append(md.Fields, &alice.FieldDescription{
+				Name:       dep.fieldName,
+				Type:       typeString(dep.tp),
+				Kind:       alice.FieldKindName,
+				Dependency: dep.name,
+			})
+		}
+		for _, dep := range m.typedDepends {
+			md.Fields = append(md.Fields, &alice.FieldDescription{
+				Name: dep.fieldName,
+				Type: typeString(dep.tp),
+				Kind: alice.FieldKindType,
+			})
+		}
+		d.Modules = append(d.Modules, md)
+	}
+	for _, edge := range g.edges {
+		kind := alice.FieldKindType
+		if edge.named {
+			kind = alice.FieldKindName
+		}
+		d.Edges = append(d.Edges, &alice.EdgeDescription{
+			From:  edge.provider.qualifiedName(edge.instance.name),
+			To:    edge.dependant.id(),
+			Field: edge.fieldName,
+			Kind:  kind,
+		})
+	}
+	for _, m := range order {
+		d.Order = append(d.Order, m.id())
+	}
+	return d
+}
+
+// typeString returns the string of a type qualified by package names, the same as reflect.Type.String.
+func typeString(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		return pkg.Name()
+	})
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// diagnostic is a problem found in the source code by lint.
+type diagnostic struct {
+	pos token.Pos
+	msg string
+}
+
+// _MalformedTagPattern matches tags which intend to use the alice key, but could not be parsed, e.g. `alice: "Name"`.
+var _MalformedTagPattern = regexp.MustCompile(`(^|\s)alice\s*:`)
+
+// runLint reports common mistakes in module definitions, in the format of "file:line:column: message" which is
+// understood by editors and CI. It returns error if any problem is found.
+func runLint(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	l := newLoader()
+	pkgs, err := l.load(patterns...)
+	if err != nil {
+		return err
+	}
+	diagnostics := lint(pkgs)
+	for _, d := range diagnostics {
+		fmt.Fprintf(stdout, "%s: %s\n", l.fset.Position(d.pos), d.msg)
+	}
+	if len(diagnostics) > 0 {
+		return fmt.Errorf("alice: %d problems found", len(diagnostics))
+	}
+	return nil
+}
+
+// lint checks the modules in the packages, and returns the problems sorted by position. The checks are:
+//   - a tagged field is unexported, so it could not be assigned.
+//   - an exported method has a value receiver, while providers require pointer receivers.
+//   - an exported method doesn't have 0 parameter or a context.Context parameter, and 1 return value or (T, error), so
+//     it is not a valid provider.
+//   - the name in a tag is not provided by any module in the packages, or is a deprecated alias.
+//   - a method listed by AliceIgnore is not defined.
+//   - the tag key is misspelled, the tag is malformed, or the name in the tag is not a valid instance name.
+func lint(pkgs []*loadedPackage) []*diagnostic {
+	var modules []*lintedModule
+	var diagnostics []*diagnostic
+	report := func(err error) {
+		if err != nil {
+			perr := err.(*positionedError)
+			diagnostics = append(diagnostics, &diagnostic{pos: perr.pos, msg: perr.msg})
+		}
+	}
+	for _, pkg := range pkgs {
+		scope := pkg.types.Scope()
+		for _, name := range sortedByPos(scope) {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok || !isModuleStruct(named) {
+				continue
+			}
+			ignored, err := ignoredMethods(pkg, named)
+			report(err)
+			namespace, err := moduleNamespace(pkg, named)
+			report(err)
+			_, err = moduleProfiles(pkg, named)
+			report(err)
+			decorators, err := moduleDecorators(pkg, named)
+			report(err)
+			if err == nil && ignored != nil {
+				for _, d := range decorators {
+					ignored[d.name] = true
+				}
+			} else {
+				ignored = nil
+			}
+			m := &lintedModule{named: named, ignored: ignored, namespace: namespace}
+			for _, method := range m.providerMethods() {
+				m.instances = append(m.instances, &staticInstance{
+					name:   method.Name(),
+					method: method.Name(),
+					pos:    method.Pos(),
+				})
+			}
+			report(applyNames(pkg, named, m.instances))
+			report(applyAliases(pkg, named, m.instances))
+			report(applyProviderProfiles(pkg, named, m.instances))
+			report(applyProviderConditions(pkg, named, m.instances))
+			report(markFallbacks(pkg, named, m.instances))
+			modules = append(modules, m)
+		}
+	}
+
+	// names maps the instance names and aliases, qualified or not, to the deprecated aliases they are.
+	names := make(map[string]*deprecatedAlias)
+	for _, m := range modules {
+		for _, instance := range m.instances {
+			m.addName(names, instance.name, nil)
+			for _, alias := range instance.aliases {
+				var deprecated *deprecatedAlias
+				if alias.deprecated {
+					deprecated = &deprecatedAlias{alias: alias.name, name: m.qualifiedName(instance.name)}
+				}
+				m.addName(names, alias.name, deprecated)
+			}
+		}
+	}
+
+	for _, m := range modules {
+		diagnostics = append(diagnostics, m.lintMethods()...)
+		diagnostics = append(diagnostics, lintFields(m.named, names)...)
+	}
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].pos < diagnostics[j].pos
+	})
+	return diagnostics
+}
+
+// lintedModule is a module to be checked by lint.
+type lintedModule struct {
+	named *types.Named
+	// ignored are the methods listed by AliceIgnore and the decorators. It is nil if AliceIgnore or AliceDecorators
+	// is invalid, so the methods are not checked to avoid reporting them again.
+	ignored   map[string]bool
+	namespace string
+	instances []*staticInstance
+}
+
+// deprecatedAlias is a deprecated alias of an instance.
+type deprecatedAlias struct {
+	alias string
+	name  string
+}
+
+// qualifiedName returns the name of an instance qualified by the namespace of the module.
+func (m *lintedModule) qualifiedName(name string) string {
+	if m.namespace == "" {
+		return name
+	}
+	return m.namespace + "." + name
+}
+
+// addName adds a name of an instance provided by the module, both unqualified and qualified by the namespace.
+func (m *lintedModule) addName(names map[string]*deprecatedAlias, name string, deprecated *deprecatedAlias) {
+	names[name] = deprecated
+	names[m.qualifiedName(name)] = deprecated
+}
+
+// providerMethods returns the exported methods of a module which are treated as providers, including the invalid
+// ones.
+func (m *lintedModule) providerMethods() []*types.Func {
+	var methods []*types.Func
+	mset := types.NewMethodSet(types.NewPointer(m.named))
+	for i := 0; i < mset.Len(); i++ {
+		if isProviderMethod(mset.At(i), m.ignored) {
+			methods = append(methods, mset.At(i).Obj().(*types.Func))
+		}
+	}
+	return methods
+}
+
+// lintMethods checks the methods declared on a module.
+func (m *lintedModule) lintMethods() []*diagnostic {
+	if m.ignored == nil {
+		return nil
+	}
+	var diagnostics []*diagnostic
+	moduleName := m.named.Obj().Name()
+	for i := 0; i < m.named.NumMethods(); i++ {
+		method := m.named.Method(i)
+		if !method.Exported() || isHookMethod(method.Name()) || m.ignored[method.Name()] {
+			continue
+		}
+		sig := method.Type().(*types.Signature)
+		if _, ok := sig.Recv().Type().(*types.Pointer); !ok {
+			diagnostics = append(diagnostics, &diagnostic{
+				pos: method.Pos(),
+				msg: fmt.Sprintf("provider %s.%s has a value receiver; use a pointer receiver",
+					moduleName, method.Name()),
+			})
+		}
+		if !isProviderSignature(sig) {
+			diagnostics = append(diagnostics, &diagnostic{
+				pos: method.Pos(),
+				msg: fmt.Sprintf("exported method %s.%s is treated as a provider, but doesn't have 0 parameter "+
+					"or a context.Context parameter, and 1 return value or (T, error); unexport it or list it in %s "+
+					"if it is a helper", moduleName, method.Name(), _AliceIgnoreMethodName),
+			})
+		}
+	}
+	return diagnostics
+}
+
+// lintFields checks the tags of the fields of a module.
+func lintFields(named *types.Named, names map[string]*deprecatedAlias) []*diagnostic {
+	var diagnostics []*diagnostic
+	report := func(pos token.Pos, format string, args ...interface{}) {
+		diagnostics = append(diagnostics, &diagnostic{
+			pos: pos,
+			msg: fmt.Sprintf(format, args...),
+		})
+	}
+
+	moduleName := named.Obj().Name()
+	st := named.Underlying().(*types.Struct)
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := st.Tag(i)
+
+		for _, key := range tagKeys(tag) {
+			if key != _Tag && isMisspelling(key, _Tag) {
+				report(field.Pos(), "tag key %q of field %s.%s looks like a misspelling of %q",
+					key, moduleName, field.Name(), _Tag)
+			}
+		}
+
+		dependName, exists := reflect.StructTag(tag).Lookup(_Tag)
+		if !exists {
+			if _MalformedTagPattern.MatchString(tag) {
+				report(field.Pos(), "malformed alice tag of field %s.%s: `%s`; expected `alice:\"Name\"` or `alice:\"\"`",
+					moduleName, field.Name(), tag)
+			}
+			continue
+		}
+
+		if field.Embedded() {
+			report(field.Pos(), "alice tag of embedded field %s.%s is ignored", moduleName, field.Name())
+			continue
+		}
+		if !field.Exported() {
+			report(field.Pos(), "tagged field %s.%s is unexported and could not be assigned; export it",
+				moduleName, field.Name())
+		}
+		if dependName == "" {
+			continue
+		}
+		if !isDependencyName(dependName) {
+			report(field.Pos(), "alice tag of field %s.%s has invalid instance name %q",
+				moduleName, field.Name(), dependName)
+		} else if deprecated, ok := names[dependName]; !ok {
+			report(field.Pos(), "no instance named %s is provided by the modules for field %s.%s",
+				dependName, moduleName, field.Name())
+		} else if deprecated != nil {
+			report(field.Pos(), "field %s.%s uses deprecated alias %s of instance %s",
+				moduleName, field.Name(), deprecated.alias, deprecated.name)
+		}
+	}
+	return diagnostics
+}
+
+// isDependencyName checks if the name is an instance name, or an instance name qualified by a namespace, e.g.
+// payments.Client.
+func isDependencyName(name string) bool {
+	if i := strings.Index(name, "."); i >= 0 {
+		return token.IsIdentifier(name[:i]) && isInstanceName(name[i+1:])
+	}
+	return isInstanceName(name)
+}
+
+// tagKeys returns the keys in a struct tag which follows the conventional format, e.g. `json:"name" alice:""`.
+func tagKeys(tag string) []string {
+	var keys []string
+	for _, part := range strings.Fields(tag) {
+		if i := strings.Index(part, ":"); i > 0 {
+			keys = append(keys, part[:i])
+		}
+	}
+	return keys
+}
+
+// isMisspelling checks if s is within one edit from the word, ignoring case. An edit is an insertion, a deletion, a
+// substitution, or a transposition of two adjacent letters. Callers exclude the word itself.
+func isMisspelling(s, word string) bool {
+	s, word = strings.ToLower(s), strings.ToLower(word)
+	if s == word {
+		return true
+	}
+	if len(s) == len(word) {
+		var diffs []int
+		for i := 0; i < len(s); i++ {
+			if s[i] != word[i] {
+				diffs = append(diffs, i)
+			}
+		}
+		if len(diffs) == 1 {
+			return true
+		}
+		return len(diffs) == 2 && diffs[1] == diffs[0]+1 &&
+			s[diffs[0]] == word[diffs[1]] && s[diffs[1]] == word[diffs[0]]
+	}
+	if len(s) > len(word) {
+		s, word = word, s
+	}
+	if len(word)-len(s) != 1 {
+		return false
+	}
+	for i := 0; i < len(word); i++ {
+		if word[:i]+word[i+1:] == s {
+			return true
+		}
+	}
+	return false
+}
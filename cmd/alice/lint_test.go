@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun_Lint(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"lint", "./testdata/lint"}, &stdout, &stderr)
+
+	if code != 1 {
+		t.Errorf("bad exit code after lint: got %d, expected 1", code)
+	}
+	expected := []string{
+		"lint.go:11:2: tagged field LintModule.unexported is unexported and could not be assigned; export it",
+		"lint.go:12:2: no instance named Missinq is provided by the modules for field LintModule.Missing",
+		`lint.go:13:2: alice tag of field LintModule.Invalid has invalid instance name "HTTP Client"`,
+		`lint.go:14:2: tag key "alcie" of field LintModule.Misspelled looks like a misspelling of "alice"`,
+		"lint.go:15:2: malformed alice tag of field LintModule.Malformed: `alice: \"Client\"`; " +
+			"expected `alice:\"Name\"` or `alice:\"\"`",
+		"lint.go:20:21: provider LintModule.Client has a value receiver; use a pointer receiver",
+		"lint.go:24:22: exported method LintModule.Format is treated as a provider, but doesn't have " +
+			"0 parameter or a context.Context parameter, and 1 return value or (T, error); unexport it or list it " +
+			"in AliceIgnore if it is a helper",
+		"lint.go:44:28: ignored method HelperModule.Unknown is not defined",
+		"lint.go:66:2: no instance named payments.Client is provided by the modules for field CheckoutModule.Missing",
+		"lint.go:85:2: field FetchingModule.Old uses deprecated alias OldFetcher of instance Fetcher",
+		"lint.go:106:2: no instance named HTTPClient is provided by the modules for field HTTPUserModule.Method",
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("bad number of problems after lint: got %d, expected %d\n%s",
+			len(lines), len(expected), stdout.String())
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, expected[i]) {
+			t.Errorf("bad problem after lint: got %q, expected to end with %q", line, expected[i])
+		}
+	}
+}
+
+func TestRun_LintExample(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"lint", _ExamplePackage}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("bad exit code after lint: got %d, expected 0, stdout: %s", code, stdout.String())
+	}
+}
+
+func TestIsMisspelling(t *testing.T) {
+	cases := map[string]bool{
+		"Alice":  true,
+		"alcie":  true,
+		"alie":   true,
+		"allice": true,
+		"alica":  true,
+		"alias":  false,
+		"json":   false,
+		"al":     false,
+	}
+	for s, expected := range cases {
+		if isMisspelling(s, "alice") != expected {
+			t.Errorf("bad result of isMisspelling(%q): got %v, expected %v", s, !expected, expected)
+		}
+	}
+}
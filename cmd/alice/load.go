@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// loadedPackage is a type-checked package parsed from source.
+type loadedPackage struct {
+	path  string
+	dir   string
+	files []*ast.File
+	types *types.Package
+	info  *types.Info
+}
+
+// loader parses and type-checks packages from source. Packages in GOROOT are imported by the source importer of the
+// standard library. Other packages are type-checked by the loader itself, so that every package is type-checked only
+// once and types are identical across packages.
+type loader struct {
+	fset     *token.FileSet
+	ctxt     *build.Context
+	std      types.ImporterFrom
+	packages map[string]*loadedPackage
+	loading  map[string]bool
+	// ignored are the absolute paths of files not to be loaded, e.g. a generated file to be replaced.
+	ignored map[string]bool
+}
+
+// newLoader creates a new loader.
+func newLoader() *loader {
+	fset := token.NewFileSet()
+	return &loader{
+		fset:     fset,
+		ctxt:     &build.Default,
+		std:      importer.ForCompiler(fset, "source", nil).(types.ImporterFrom),
+		packages: make(map[string]*loadedPackage),
+		loading:  make(map[string]bool),
+		ignored:  make(map[string]bool),
+	}
+}
+
+// ignore makes the loader skip the file when parsing packages.
+func (l *loader) ignore(filename string) error {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return err
+	}
+	l.ignored[abs] = true
+	return nil
+}
+
+// load loads the packages matching the patterns. A pattern is either a directory, a directory followed by "/..." for
+// all the packages under it, or an import path.
+func (l *loader) load(patterns ...string) ([]*loadedPackage, error) {
+	var pkgs []*loadedPackage
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		bpkgs, err := l.resolve(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, bpkg := range bpkgs {
+			if seen[bpkg.ImportPath] {
+				continue
+			}
+			seen[bpkg.ImportPath] = true
+			pkg, err := l.check(bpkg)
+			if err != nil {
+				return nil, err
+			}
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs, nil
+}
+
+// resolve finds the packages matching a pattern.
+func (l *loader) resolve(pattern string) ([]*build.Package, error) {
+	if strings.HasSuffix(pattern, "/...") {
+		root := strings.TrimSuffix(pattern, "/...")
+		var bpkgs []*build.Package
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return nil
+			}
+			name := info.Name()
+			if path != root && (name == "testdata" || name == "vendor" ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			bpkg, err := l.importDir(path)
+			if err != nil {
+				if _, ok := err.(*build.NoGoError); ok {
+					return nil
+				}
+				return err
+			}
+			bpkgs = append(bpkgs, bpkg)
+			return nil
+		})
+		return bpkgs, err
+	}
+
+	if build.IsLocalImport(pattern) || filepath.IsAbs(pattern) {
+		bpkg, err := l.importDir(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return []*build.Package{bpkg}, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	bpkg, err := l.ctxt.Import(pattern, wd, 0)
+	if err != nil {
+		return nil, err
+	}
+	return []*build.Package{bpkg}, nil
+}
+
+// importDir finds the package in a directory. In module mode, the import path is computed from go.mod. The directory
+// is made absolute first, because in GOPATH mode the import path of a relative directory is ".".
+func (l *loader) importDir(dir string) (*build.Package, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	bpkg, err := l.ctxt.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	if bpkg.ImportPath == "" || bpkg.ImportPath == "." {
+		path, err := localImportPath(bpkg.Dir)
+		if err != nil {
+			return nil, err
+		}
+		bpkg.ImportPath = path
+	}
+	return bpkg, nil
+}
+
+// check parses and type-checks a package. Packages already checked are returned from cache.
+func (l *loader) check(bpkg *build.Package) (*loadedPackage, error) {
+	path := bpkg.ImportPath
+	if pkg, ok := l.packages[path]; ok {
+		return pkg, nil
+	}
+	if l.loading[path] {
+		return nil, fmt.Errorf("import cycle through package %s", path)
+	}
+	l.loading[path] = true
+	defer delete(l.loading, path)
+
+	var files []*ast.File
+	for _, name := range bpkg.GoFiles {
+		filename, err := filepath.Abs(filepath.Join(bpkg.Dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if l.ignored[filename] {
+			continue
+		}
+		f, err := parser.ParseFile(l.fset, filename, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	conf := &types.Config{
+		Importer: importerFunc(func(importPath, dir string) (*types.Package, error) {
+			return l.importFrom(importPath, dir)
+		}),
+	}
+	tpkg, err := conf.Check(path, l.fset, files, info)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &loadedPackage{
+		path:  path,
+		dir:   bpkg.Dir,
+		files: files,
+		types: tpkg,
+		info:  info,
+	}
+	l.packages[path] = pkg
+	return pkg, nil
+}
+
+// importFrom imports a package required by a package being type-checked.
+func (l *loader) importFrom(path, dir string) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if pkg, ok := l.packages[path]; ok {
+		return pkg.types, nil
+	}
+	bpkg, err := l.ctxt.Import(path, dir, build.FindOnly)
+	if err != nil {
+		return nil, err
+	}
+	if bpkg.Goroot {
+		return l.std.ImportFrom(path, dir, 0)
+	}
+	bpkg, err = l.ctxt.Import(path, dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := l.check(bpkg)
+	if err != nil {
+		return nil, err
+	}
+	return pkg.types, nil
+}
+
+// importerFunc implements types.ImporterFrom with a function.
+type importerFunc func(path, dir string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path, "")
+}
+
+func (f importerFunc) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	return f(path, dir)
+}
+
+// localImportPath computes the import path of a package directory in module mode. It is the module path in the
+// closest go.mod file joined with the relative directory. If there is no go.mod file, the absolute directory is used.
+func localImportPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for root := abs; ; root = filepath.Dir(root) {
+		data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			modulePath := modulePath(data)
+			if modulePath == "" {
+				break
+			}
+			rel, err := filepath.Rel(root, abs)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return modulePath, nil
+			}
+			return modulePath + "/" + filepath.ToSlash(rel), nil
+		}
+		if filepath.Dir(root) == root {
+			break
+		}
+	}
+	return abs, nil
+}
+
+// modulePath returns the module path declared in a go.mod file, or empty if not found.
+func modulePath(gomod []byte) string {
+	for _, line := range strings.Split(string(gomod), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,155 @@
+// Command alice statically analyzes the alice modules in Go packages, without running any code.
+//
+// Every struct embedding alice.BaseModule in the packages is a module. The modules are analyzed with the same rules
+// as alice.CreateContainer, so wiring errors could be caught in CI before any binary starts. The AliceIgnore,
+// AliceNamespace, AliceAliases, AliceNames, AliceProfiles, AliceProviderProfiles, AliceConditions, AliceFallbacks and
+// AliceDecorators methods of a module are evaluated without running them, so they must return literals of constants.
+//
+// Usage:
+//
+//	alice check [-profiles list] [packages]                             check the modules for wiring errors
+//	alice graph [-profiles list] [-format=dot|mermaid|json] [packages]  print the dependency graph
+//	alice order [-profiles list] [packages]                             print the instantiation order of the modules
+//	alice generate [-profiles list] [-o file] [-type name] [packages]   generate wiring code without reflection
+//	alice lint [packages]                                               report common mistakes in module definitions
+//
+// A package is either a directory, a directory followed by "/..." for all the packages under it, or an import path.
+// It defaults to the current directory.
+//
+// The generate command writes a Go file to the first package, which constructs the modules in instantiation order
+// with plain assignments and method calls. The generated container type has a field for each instance, and
+// implements alice.Container, so it could replace a container created by alice.CreateContainer. Wiring errors become
+// compile errors of the generated code. Providers taking a context.Context get it from a ctx parameter, and the
+// generated function returns the error of a provider returning (T, error), without retrying it by the policies of
+// AliceRetryPolicies. It is usually run by go:generate:
+//
+//	//go:generate alice generate .
+//
+// The lint command reports common mistakes at source positions, in the format of "file:line:column: message" which
+// is understood by editors and CI. See lint for the checks.
+//
+// Modules and providers are analyzed as if the comma separated profiles passed by -profiles were activated by
+// alice.WithProfiles, so the generated code wires the modules of those profiles only. Other options passed to
+// alice.CreateContainer at runtime, such as overrides and naming strategies, are not taken into account.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const _Usage = `Usage:
+
+	alice check [-profiles list] [packages]                             check the modules for wiring errors
+	alice graph [-profiles list] [-format=dot|mermaid|json] [packages]  print the dependency graph
+	alice order [-profiles list] [packages]                             print the instantiation order of the modules
+	alice generate [-profiles list] [-o file] [-type name] [packages]   generate wiring code without reflection
+	alice lint [packages]                                               report common mistakes in module definitions
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command with the arguments, and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, _Usage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "check":
+		err = runCheck(args[1:], stdout)
+	case "graph":
+		err = runGraph(args[1:], stdout)
+	case "order":
+		err = runOrder(args[1:], stdout)
+	case "generate":
+		err = runGenerate(args[1:], stdout)
+	case "lint":
+		err = runLint(args[1:], stdout)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, _Usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "alice: unknown command %q\n\n%s", args[0], _Usage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return 1
+	}
+	return 0
+}
+
+// runCheck checks the modules in the packages.
+func runCheck(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	var profiles profilesFlag
+	flags.Var(&profiles, "profiles", "comma separated active profiles")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	a, err := analyze(newLoader(), flags.Args(), profiles)
+	if err != nil {
+		return err
+	}
+	instances := 0
+	for _, m := range a.modules {
+		instances += len(m.instances)
+	}
+	fmt.Fprintf(stdout, "ok: %d modules, %d instances\n", len(a.modules), instances)
+	return nil
+}
+
+// runGraph prints the dependency graph of the modules in the packages.
+func runGraph(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("graph", flag.ContinueOnError)
+	var profiles profilesFlag
+	flags.Var(&profiles, "profiles", "comma separated active profiles")
+	format := flags.String("format", "dot", "output format: dot, mermaid or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	a, err := analyze(newLoader(), flags.Args(), profiles)
+	if err != nil {
+		return err
+	}
+	d := a.graph.describe(a.order)
+	switch *format {
+	case "dot":
+		return d.WriteDOT(stdout)
+	case "mermaid":
+		return d.WriteMermaid(stdout)
+	case "json":
+		return d.WriteJSON(stdout)
+	default:
+		return fmt.Errorf("alice: unknown format %q", *format)
+	}
+}
+
+// runOrder prints the instantiation order of the modules in the packages.
+func runOrder(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("order", flag.ContinueOnError)
+	var profiles profilesFlag
+	flags.Var(&profiles, "profiles", "comma separated active profiles")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	a, err := analyze(newLoader(), flags.Args(), profiles)
+	if err != nil {
+		return err
+	}
+	for _, m := range a.order {
+		fmt.Fprintf(stdout, "%s.%s\n", m.tp.Obj().Pkg().Name(), m.name)
+	}
+	return nil
+}
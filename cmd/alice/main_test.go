@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/magic003/alice"
+	"github.com/magic003/alice/example/module"
+)
+
+const _ExamplePackage = "../../example/module"
+
+// exampleModules returns the example modules in the order of declaration.
+func exampleModules() []alice.Module {
+	return []alice.Module{
+		&module.BusinessModule{}, &module.ClientModule{}, &module.ConfigModule{}, &module.PersistModule{},
+	}
+}
+
+func TestRun_Check(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"check", _ExamplePackage}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("bad exit code after check: got %d, expected 0, stderr: %s", code, stderr.String())
+	}
+	expected := "ok: 4 modules, 5 instances\n"
+	if stdout.String() != expected {
+		t.Errorf("bad output after check: got %q, expected %q", stdout.String(), expected)
+	}
+}
+
+func TestRun_CheckErrors(t *testing.T) {
+	cases := []struct {
+		pkg      string
+		expected string
+	}{
+		{"./testdata/invalid",
+			"invalid.go:9:25: method InvalidModule.Foo doesn't have 0 parameter or a context.Context " +
+				"parameter, and 1 return value or (T, error)"},
+		{"./testdata/cycle", "cyclic dependencies for modules: AModule -> BModule -> AModule"},
+		{"./testdata/mismatch", "mismatch.go:15:2: dependency name ClientModule.Retries of type int " +
+			"is not assignable from ConfigModule.Retries of type string"},
+		{"./testdata/ambiguous", "ambiguous.go:33:2: dependency type CModule.ambiguous.Client " +
+			"is found in mutiple modules: [AModule BModule]"},
+		{"./testdata/ambiguousname", "ambiguousname.go:37:2: dependency name CheckoutModule.Client is ambiguous, " +
+			"use one of the qualified names [payments.Client shipping.Client]"},
+		{"./testdata/embedvalue", "embedvalue.go:26:2: module LoggingModule embedded by value in " +
+			"ServerModule.LoggingModule is included more than once; embed it by pointer to share a single instance"},
+	}
+
+	for _, c := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"check", c.pkg}, &stdout, &stderr)
+
+		if code != 1 {
+			t.Errorf("bad exit code after check %s: got %d, expected 1", c.pkg, code)
+		}
+		if !strings.Contains(stderr.String(), c.expected) {
+			t.Errorf("bad error after check %s: got %q, expected to contain %q", c.pkg, stderr.String(), c.expected)
+		}
+	}
+}
+
+func TestRun_Graph(t *testing.T) {
+	var runtimeJSON bytes.Buffer
+	if err := alice.ExportJSON(&runtimeJSON, exampleModules()...); err != nil {
+		t.Fatalf("unexpected error after ExportJSON(): %s", err.Error())
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"graph", "-format=json", _ExamplePackage}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("bad exit code after graph: got %d, expected 0, stderr: %s", code, stderr.String())
+	}
+
+	var static, runtime alice.GraphDescription
+	if err := json.Unmarshal(stdout.Bytes(), &static); err != nil {
+		t.Fatalf("unexpected error decoding static graph: %s", err.Error())
+	}
+	if err := json.Unmarshal(runtimeJSON.Bytes(), &runtime); err != nil {
+		t.Fatalf("unexpected error decoding runtime graph: %s", err.Error())
+	}
+	if !reflect.DeepEqual(static, runtime) {
+		t.Errorf("bad static graph: got %s, expected %s", stdout.String(), runtimeJSON.String())
+	}
+}
+
+func TestRun_GraphFormats(t *testing.T) {
+	for _, format := range []string{"dot", "mermaid"} {
+		var expected bytes.Buffer
+		var err error
+		if format == "dot" {
+			err = alice.ExportDOT(&expected, exampleModules()...)
+		} else {
+			err = alice.ExportMermaid(&expected, exampleModules()...)
+		}
+		if err != nil {
+			t.Fatalf("unexpected error after exporting %s: %s", format, err.Error())
+		}
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"graph", "-format=" + format, _ExamplePackage}, &stdout, &stderr)
+		if code != 0 {
+			t.Errorf("bad exit code after graph: got %d, expected 0, stderr: %s", code, stderr.String())
+		}
+		if stdout.String() != expected.String() {
+			t.Errorf("bad %s graph: got\n%s\nexpected\n%s", format, stdout.String(), expected.String())
+		}
+	}
+}
+
+func TestRun_Order(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"order", _ExamplePackage}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("bad exit code after order: got %d, expected 0, stderr: %s", code, stderr.String())
+	}
+	expected := "module.ConfigModule\nmodule.PersistModule\nmodule.ClientModule\nmodule.BusinessModule\n"
+	if stdout.String() != expected {
+		t.Errorf("bad output after order: got %q, expected %q", stdout.String(), expected)
+	}
+}
+
+func TestRun_OrderEmbeddedModules(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"order", "./testdata/compose"}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("bad exit code after order: got %d, expected 0, stderr: %s", code, stderr.String())
+	}
+	expected := "module.ConfigModule\ncompose.LoggingModule\ncompose.ServerModule\n"
+	if stdout.String() != expected {
+		t.Errorf("bad output after order: got %q, expected %q", stdout.String(), expected)
+	}
+}
+
+func TestRun_GraphEmbeddedNamespace(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"graph", "-format=json", "./testdata/compose"}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("bad exit code after graph: got %d, expected 0, stderr: %s", code, stderr.String())
+	}
+	// The namespace of the embedded LoggingModule doesn't apply to the providers of ServerModule.
+	for _, name := range []string{`"name": "logging.Logger"`, `"name": "Address"`} {
+		if !strings.Contains(stdout.String(), name) {
+			t.Errorf("bad output after graph: got %s, expected to contain %s", stdout.String(), name)
+		}
+	}
+}
+
+func TestRun_OrderOverriddenProviders(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"order", "./testdata/inherit"}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("bad exit code after order: got %d, expected 0, stderr: %s", code, stderr.String())
+	}
+	// CustomizedModule is instantiated after VendorModule, whose Client it calls.
+	expected := "inherit.ConfigModule\ninherit.VendorModule\ninherit.CustomizedModule\ninherit.ServiceModule\n"
+	if stdout.String() != expected {
+		t.Errorf("bad output after order: got %q, expected %q", stdout.String(), expected)
+	}
+}
+
+func TestRun_OrderProfiles(t *testing.T) {
+	cases := map[string]string{
+		"prod":    "profile.ConfigModule\nprofile.MySQLDAOModule\nprofile.ServiceModule\n",
+		"dev,eu":  "profile.ConfigModule\nprofile.InMemoryDAOModule\nprofile.ServiceModule\n",
+		"default": "profile.ConfigModule\nprofile.InMemoryDAOModule\nprofile.ServiceModule\n",
+	}
+	for profiles, expected := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"order", "-profiles", profiles, "./testdata/profile"}, &stdout, &stderr)
+
+		if code != 0 {
+			t.Errorf("bad exit code after order -profiles %s: got %d, expected 0, stderr: %s",
+				profiles, code, stderr.String())
+		}
+		if stdout.String() != expected {
+			t.Errorf("bad output after order -profiles %s: got %q, expected %q", profiles, stdout.String(), expected)
+		}
+	}
+}
+
+func TestRun_CheckConditions(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"check", "./testdata/condition"}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("bad exit code after check: got %d, expected 0, stderr: %s", code, stderr.String())
+	}
+	// DefaultClient is dropped for Client of AppModule, and MetricsSink for missing Registry.
+	expected := "ok: 3 modules, 1 instances\n"
+	if stdout.String() != expected {
+		t.Errorf("bad output after check: got %q, expected %q", stdout.String(), expected)
+	}
+}
+
+func TestRun_CheckEmbeddedHooks(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"check", "./testdata/composehooks"}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("bad exit code after check: got %d, expected 0, stderr: %s", code, stderr.String())
+	}
+	// ProdModule is not in the default profile, and FakeClient of VendorModule is in dev only.
+	expected := "ok: 2 modules, 3 instances\n"
+	if stdout.String() != expected {
+		t.Errorf("bad output after check: got %q, expected %q", stdout.String(), expected)
+	}
+}
+
+func TestRun_CheckFallbacks(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"check", "./testdata/fallback"}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("bad exit code after check: got %d, expected 0, stderr: %s", code, stderr.String())
+	}
+	// The fallbacks of LoggingModule are kept, while ServiceModule.Logger is TeamLogger of TeamModule rather than a
+	// fallback. payments.Client of PaymentsModule is kept because orders.Client of OrdersModule is in another
+	// namespace.
+	expected := "ok: 6 modules, 7 instances\n"
+	if stdout.String() != expected {
+		t.Errorf("bad output after check: got %q, expected %q", stdout.String(), expected)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(nil, &stdout, &stderr); code != 2 {
+		t.Errorf("bad exit code without command: got %d, expected 2", code)
+	}
+	if code := run([]string{"unknown"}, &stdout, &stderr); code != 2 {
+		t.Errorf("bad exit code on unknown command: got %d, expected 2", code)
+	}
+}
@@ -0,0 +1,764 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"unicode"
+)
+
+const (
+	_AlicePackagePath         = "github.com/magic003/alice"
+	_BaseModuleName           = "BaseModule"
+	_Tag                      = "alice"
+	_IsModuleMethodName       = "IsModule"
+	_AliceIgnoreMethodName    = "AliceIgnore"
+	_AliceNamespaceMethodName = "AliceNamespace"
+	_AliceAliasesMethodName   = "AliceAliases"
+	_AliceNamesMethodName     = "AliceNames"
+
+	_AliceProfilesMethodName         = "AliceProfiles"
+	_AliceProviderProfilesMethodName = "AliceProviderProfiles"
+	_AliceConditionsMethodName       = "AliceConditions"
+	_AliceFallbacksMethodName        = "AliceFallbacks"
+	_AliceDecoratorsMethodName       = "AliceDecorators"
+	_AliceRetryPoliciesMethodName    = "AliceRetryPolicies"
+)
+
+// staticModule contains the instance and dependency information of a module. It is the static counterpart of the
+// reflectedModule in package alice, and follows the same rules.
+type staticModule struct {
+	name      string
+	namespace string
+	profiles  []string
+	pkg       *loadedPackage
+	tp        *types.Named
+	pos       token.Pos
+
+	instances    []*staticInstance
+	namedDepends []*staticNamedField
+	typedDepends []*staticTypedField
+
+	// embedding is the first module found embedding this module, if any.
+	embedding *staticEmbedding
+	// embedded are the modules embedded in the module.
+	embedded []*staticModule
+	// bases are the embedded modules whose providers are overridden by the module.
+	bases []*staticModule
+	// decorators are the methods of the module decorating instances, sorted by name.
+	decorators []*staticDecorator
+}
+
+// staticEmbedding is a field of a module which embeds another module.
+type staticEmbedding struct {
+	parent    *staticModule
+	fieldName string
+	pointer   bool
+}
+
+type staticInstance struct {
+	name string
+	// method is the name of the provider method. It is the same as name unless the instance is named by AliceNames.
+	method     string
+	tp         types.Type
+	pos        token.Pos
+	aliases    []*staticAlias
+	profiles   []string
+	conditions []*staticCondition
+	fallback   bool
+	// decorators are applied to the instance in order before it is published.
+	decorators []*staticDecorator
+	// withContext is true if the provider method takes a context.Context.
+	withContext bool
+	// withError is true if the provider method returns (T, error).
+	withError bool
+}
+
+// staticAlias is the static counterpart of alice.Alias.
+type staticAlias struct {
+	name       string
+	deprecated bool
+	pos        token.Pos
+}
+
+type staticNamedField struct {
+	name      string
+	fieldName string
+	tp        types.Type
+	pos       token.Pos
+}
+
+type staticTypedField struct {
+	tp        types.Type
+	fieldName string
+	pos       token.Pos
+}
+
+// positionedError is an error at a position of the source code.
+type positionedError struct {
+	pos token.Pos
+	msg string
+}
+
+func (e *positionedError) Error() string {
+	return e.msg
+}
+
+// errorf creates a positionedError.
+func errorf(pos token.Pos, format string, args ...interface{}) *positionedError {
+	return &positionedError{
+		pos: pos,
+		msg: fmt.Sprintf(format, args...),
+	}
+}
+
+// analyzeModules finds the module structs in the packages, and analyzes them in the order of packages and
+// declarations. Modules embedded in them are analyzed as well, even if they are declared in other packages. Each module
+// type is analyzed only once, the same as a module shared by pointer at runtime, so a module type embedded by value in
+// more than one module is an error, the same as at runtime. Modules and providers which are not active in the profiles
+// are skipped. It returns the errors of all invalid modules.
+func analyzeModules(l *loader, pkgs []*loadedPackage, profiles profileSet) ([]*staticModule, []error) {
+	var modules []*staticModule
+	var errs []error
+	seen := make(map[*types.Named]bool)
+	byType := make(map[*types.Named]*staticModule)
+	// embeddings is the first field embedding each module type.
+	embeddings := make(map[*types.Named]*embeddedField)
+	var add func(pkg *loadedPackage, named *types.Named)
+	add = func(pkg *loadedPackage, named *types.Named) {
+		if seen[named] {
+			return
+		}
+		seen[named] = true
+		m, err := analyzeModule(pkg, named)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		if !profiles.matches(m.profiles) {
+			return
+		}
+		modules = append(modules, m)
+		byType[named] = m
+		m.removeInactiveProviders(profiles)
+
+		embedded, err := embeddedModules(named)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		for _, em := range embedded {
+			epkg, ok := l.packages[em.tp.Obj().Pkg().Path()]
+			if !ok {
+				errs = append(errs, errorf(em.tp.Obj().Pos(), "package of embedded module %s is not loaded",
+					em.tp.Obj().Name()))
+				continue
+			}
+			first, ok := embeddings[em.tp]
+			if !ok {
+				embeddings[em.tp] = em
+			} else if !first.pointer || !em.pointer {
+				byValue := first
+				if first.pointer {
+					byValue = em
+				}
+				errs = append(errs, errorf(em.pos, "module %s embedded by value in %s.%s is included more than once; "+
+					"embed it by pointer to share a single instance", em.tp.Obj().Name(), byValue.parent,
+					byValue.fieldName))
+				continue
+			}
+			add(epkg, em.tp)
+			child, ok := byType[em.tp]
+			if !ok {
+				continue
+			}
+			m.embedded = append(m.embedded, child)
+			if child.embedding == nil && !m.embeddedIn(child) {
+				child.embedding = &staticEmbedding{
+					parent:    m,
+					fieldName: em.fieldName,
+					pointer:   em.pointer,
+				}
+			}
+		}
+		m.overrideEmbeddedProviders()
+	}
+
+	for _, pkg := range pkgs {
+		scope := pkg.types.Scope()
+		for _, name := range sortedByPos(scope) {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok || !isModuleStruct(named) {
+				continue
+			}
+			add(pkg, named)
+		}
+	}
+	return modules, errs
+}
+
+// sortedByPos returns the names in the scope in the order of declaration.
+func sortedByPos(scope *types.Scope) []string {
+	names := scope.Names()
+	sort.Slice(names, func(i, j int) bool {
+		return scope.Lookup(names[i]).Pos() < scope.Lookup(names[j]).Pos()
+	})
+	return names
+}
+
+// isModuleStruct checks if the type is a struct implementing alice.Module by alice.BaseModule, which is either
+// embedded directly or through embedded modules.
+func isModuleStruct(named *types.Named) bool {
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return false
+	}
+	sel := types.NewMethodSet(types.NewPointer(named)).Lookup(nil, _IsModuleMethodName)
+	if sel == nil {
+		return false
+	}
+	recv := sel.Obj().Type().(*types.Signature).Recv().Type()
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	return isBaseModule(recv)
+}
+
+// embeddedField is a field embedding a module.
+type embeddedField struct {
+	tp *types.Named
+	// parent is the name of the module embedding the field.
+	parent    string
+	fieldName string
+	pointer   bool
+	pos       token.Pos
+}
+
+// embeddedModules returns the modules embedded in a module struct, other than alice.BaseModule. It returns error if an
+// embedded module is unexported, the same as embeddedModules in package alice.
+func embeddedModules(named *types.Named) ([]*embeddedField, error) {
+	var fields []*embeddedField
+	st := named.Underlying().(*types.Struct)
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Embedded() {
+			continue
+		}
+		t := field.Type()
+		ptr, pointer := t.(*types.Pointer)
+		if pointer {
+			t = ptr.Elem()
+		}
+		embedded, ok := t.(*types.Named)
+		if !ok || isBaseModule(embedded) || !isModuleStruct(embedded) {
+			continue
+		}
+		if !field.Exported() {
+			return nil, errorf(field.Pos(), "embedded module %s.%s is not exported", named.Obj().Name(), field.Name())
+		}
+		fields = append(fields, &embeddedField{
+			tp:        embedded,
+			parent:    named.Obj().Name(),
+			fieldName: field.Name(),
+			pointer:   pointer,
+			pos:       field.Pos(),
+		})
+	}
+	return fields, nil
+}
+
+// isBaseModule checks if the type is alice.BaseModule.
+func isBaseModule(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == _AlicePackagePath && obj.Name() == _BaseModuleName
+}
+
+// analyzeModule creates a staticModule from a module type. It returns error if the module is not properly defined,
+// the same as reflectModule in package alice.
+func analyzeModule(pkg *loadedPackage, named *types.Named) (*staticModule, error) {
+	name := named.Obj().Name()
+	ignored, err := ignoredMethods(pkg, named)
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := moduleNamespace(pkg, named)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := moduleProfiles(pkg, named)
+	if err != nil {
+		return nil, err
+	}
+	decorators, err := moduleDecorators(pkg, named)
+	if err != nil {
+		return nil, err
+	}
+
+	// get instances. The method set of the pointer type is sorted by name, the same as reflection.
+	var instances []*staticInstance
+	mset := types.NewMethodSet(types.NewPointer(named))
+	for i := 0; i < mset.Len(); i++ {
+		method := mset.At(i).Obj().(*types.Func)
+		if !isProviderMethod(mset.At(i), ignored) || isDecorator(decorators, method.Name()) {
+			continue
+		}
+		sig := method.Type().(*types.Signature)
+		if !isProviderSignature(sig) {
+			return nil, errorf(method.Pos(), "method %s.%s doesn't have 0 parameter or a context.Context "+
+				"parameter, and 1 return value or (T, error)", name, method.Name())
+		}
+		instances = append(instances, &staticInstance{
+			name:        method.Name(),
+			method:      method.Name(),
+			tp:          sig.Results().At(0).Type(),
+			pos:         method.Pos(),
+			withContext: takesContext(sig),
+			withError:   returnsError(sig),
+		})
+	}
+
+	if err := applyNames(pkg, named, instances); err != nil {
+		return nil, err
+	}
+	if err := applyAliases(pkg, named, instances); err != nil {
+		return nil, err
+	}
+	if err := applyProviderProfiles(pkg, named, instances); err != nil {
+		return nil, err
+	}
+	if err := applyProviderConditions(pkg, named, instances); err != nil {
+		return nil, err
+	}
+	if err := markFallbacks(pkg, named, instances); err != nil {
+		return nil, err
+	}
+
+	// get dependencies
+	st := named.Underlying().(*types.Struct)
+	var namedDepends []*staticNamedField
+	var typedDepends []*staticTypedField
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if field.Embedded() {
+			continue
+		}
+
+		if dependName, exists := reflect.StructTag(st.Tag(i)).Lookup(_Tag); exists {
+			if dependName != "" {
+				namedDepends = append(namedDepends, &staticNamedField{
+					name:      dependName,
+					fieldName: field.Name(),
+					tp:        field.Type(),
+					pos:       field.Pos(),
+				})
+			} else {
+				typedDepends = append(typedDepends, &staticTypedField{
+					tp:        field.Type(),
+					fieldName: field.Name(),
+					pos:       field.Pos(),
+				})
+			}
+		}
+	}
+
+	m := &staticModule{
+		name:         name,
+		namespace:    namespace,
+		profiles:     profiles,
+		pkg:          pkg,
+		tp:           named,
+		pos:          named.Obj().Pos(),
+		instances:    instances,
+		namedDepends: namedDepends,
+		typedDepends: typedDepends,
+		decorators:   decorators,
+	}
+	for _, d := range decorators {
+		d.m = m
+	}
+	return m, nil
+}
+
+// isHookMethod checks if the method is defined by alice for modules to implement, rather than a provider.
+func isHookMethod(name string) bool {
+	switch name {
+	case _IsModuleMethodName, _AliceIgnoreMethodName, _AliceNamespaceMethodName, _AliceAliasesMethodName,
+		_AliceNamesMethodName, _AliceProfilesMethodName, _AliceProviderProfilesMethodName, _AliceConditionsMethodName,
+		_AliceFallbacksMethodName, _AliceDecoratorsMethodName, _AliceRetryPoliciesMethodName:
+		return true
+	}
+	return false
+}
+
+// isProviderMethod checks if a method in the method set of a module is treated as a provider. Methods promoted from
+// embedded fields are not providers.
+func isProviderMethod(sel *types.Selection, ignored map[string]bool) bool {
+	name := sel.Obj().Name()
+	return sel.Obj().Exported() && !isHookMethod(name) && !ignored[name] && len(sel.Index()) == 1
+}
+
+// lookupHook finds the hook method with the name declared by the module itself. A hook promoted from an embedded
+// module belongs to that module.
+func lookupHook(named *types.Named, name string) *types.Selection {
+	sel := types.NewMethodSet(types.NewPointer(named)).Lookup(named.Obj().Pkg(), name)
+	if sel == nil || len(sel.Index()) != 1 {
+		return nil
+	}
+	return sel
+}
+
+// ignoredMethods returns the methods ignored by the module if it declares the AliceIgnore method in the package. The
+// method is evaluated statically, so it must return a slice literal of constant strings. It returns error if the
+// method could not be evaluated, or an ignored method is not defined.
+func ignoredMethods(pkg *loadedPackage, named *types.Named) (map[string]bool, error) {
+	ignored := make(map[string]bool)
+	mset := types.NewMethodSet(types.NewPointer(named))
+	sel := mset.Lookup(named.Obj().Pkg(), _AliceIgnoreMethodName)
+	if sel == nil {
+		return ignored, nil
+	}
+
+	name := named.Obj().Name()
+	invalid := errorf(sel.Obj().Pos(), "method %s.%s could not be evaluated statically; return a slice literal of "+
+		"constant strings", name, _AliceIgnoreMethodName)
+	lit, ok := returnedExpr(pkg, sel.Obj()).(*ast.CompositeLit)
+	if !ok {
+		return nil, invalid
+	}
+	for _, elt := range lit.Elts {
+		value := pkg.info.Types[elt].Value
+		if value == nil || value.Kind() != constant.String {
+			return nil, invalid
+		}
+		ignoredName := constant.StringVal(value)
+		if mset.Lookup(named.Obj().Pkg(), ignoredName) == nil {
+			return nil, errorf(elt.Pos(), "ignored method %s.%s is not defined", name, ignoredName)
+		}
+		ignored[ignoredName] = true
+	}
+	return ignored, nil
+}
+
+// moduleNamespace returns the namespace of the module if it declares the AliceNamespace method in the package. The
+// method is evaluated statically, so it must return a constant string. It returns error if the method could not be
+// evaluated, or the namespace is not a valid identifier.
+func moduleNamespace(pkg *loadedPackage, named *types.Named) (string, error) {
+	sel := lookupHook(named, _AliceNamespaceMethodName)
+	if sel == nil {
+		return "", nil
+	}
+
+	expr := returnedExpr(pkg, sel.Obj())
+	if expr == nil {
+		return "", errorf(sel.Obj().Pos(), "method %s.%s could not be evaluated statically; return a constant string",
+			named.Obj().Name(), _AliceNamespaceMethodName)
+	}
+	value := pkg.info.Types[expr].Value
+	if value == nil || value.Kind() != constant.String {
+		return "", errorf(expr.Pos(), "method %s.%s could not be evaluated statically; return a constant string",
+			named.Obj().Name(), _AliceNamespaceMethodName)
+	}
+	namespace := constant.StringVal(value)
+	if !token.IsIdentifier(namespace) {
+		return "", errorf(expr.Pos(), "namespace %q of module %s is not a valid identifier",
+			namespace, named.Obj().Name())
+	}
+	return namespace, nil
+}
+
+// applyAliases sets the aliases of the instances if the module declares the AliceAliases method in the package. The
+// method is evaluated statically, so it must return a map literal whose keys are constant strings and whose values
+// are slice literals of alice.Alias literals with constant fields. It returns error if the method could not be
+// evaluated, a provider is not defined, or an alias is not a valid instance name.
+func applyAliases(pkg *loadedPackage, named *types.Named, instances []*staticInstance) error {
+	sel := lookupHook(named, _AliceAliasesMethodName)
+	if sel == nil {
+		return nil
+	}
+
+	name := named.Obj().Name()
+	invalid := errorf(sel.Obj().Pos(), "method %s.%s could not be evaluated statically; return a map literal of "+
+		"alice.Alias literals with constant fields", name, _AliceAliasesMethodName)
+	lit, ok := returnedExpr(pkg, sel.Obj()).(*ast.CompositeLit)
+	if !ok {
+		return invalid
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return invalid
+		}
+		key := pkg.info.Types[kv.Key].Value
+		values, ok := kv.Value.(*ast.CompositeLit)
+		if key == nil || key.Kind() != constant.String || !ok {
+			return invalid
+		}
+		method := constant.StringVal(key)
+		instance := findStaticInstance(instances, method)
+		if instance == nil {
+			return errorf(kv.Key.Pos(), "aliased provider %s.%s is not defined", name, method)
+		}
+		for _, value := range values.Elts {
+			alias, ok := evalAlias(pkg, value)
+			if !ok {
+				return invalid
+			}
+			if !isInstanceName(alias.name) {
+				return errorf(alias.pos, "alias %q of %s.%s is not a valid instance name", alias.name, name, method)
+			}
+			instance.aliases = append(instance.aliases, alias)
+		}
+	}
+	return nil
+}
+
+// applyNames sets the names of the instances if the module declares the AliceNames method in the package. The method
+// is evaluated statically, so it must return a map literal of constant strings. It returns error if the method could
+// not be evaluated, a provider is not defined, or a name is invalid.
+func applyNames(pkg *loadedPackage, named *types.Named, instances []*staticInstance) error {
+	sel := lookupHook(named, _AliceNamesMethodName)
+	if sel == nil {
+		return nil
+	}
+
+	name := named.Obj().Name()
+	invalid := errorf(sel.Obj().Pos(), "method %s.%s could not be evaluated statically; return a map literal of "+
+		"constant strings", name, _AliceNamesMethodName)
+	lit, ok := returnedExpr(pkg, sel.Obj()).(*ast.CompositeLit)
+	if !ok {
+		return invalid
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return invalid
+		}
+		key := pkg.info.Types[kv.Key].Value
+		value := pkg.info.Types[kv.Value].Value
+		if key == nil || key.Kind() != constant.String || value == nil || value.Kind() != constant.String {
+			return invalid
+		}
+		method := constant.StringVal(key)
+		instance := findStaticInstance(instances, method)
+		if instance == nil {
+			return errorf(kv.Key.Pos(), "named provider %s.%s is not defined", name, method)
+		}
+		instanceName := constant.StringVal(value)
+		if !isInstanceName(instanceName) {
+			return errorf(kv.Value.Pos(), "name %q of %s.%s is not a valid instance name", instanceName, name, method)
+		}
+		instance.name = instanceName
+	}
+	return nil
+}
+
+// findStaticInstance finds the instance provided by the method with the name, or nil if not found.
+func findStaticInstance(instances []*staticInstance, method string) *staticInstance {
+	for _, instance := range instances {
+		if instance.method == method {
+			return instance
+		}
+	}
+	return nil
+}
+
+// isInstanceName checks if the name is a valid instance name, which consists of letters, digits, underscores and
+// hyphens, the same as package alice.
+func isInstanceName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// evalAlias evaluates an alice.Alias literal, whose fields are either keyed or positional constants.
+func evalAlias(pkg *loadedPackage, expr ast.Expr) (*staticAlias, bool) {
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil, false
+	}
+	alias := &staticAlias{pos: lit.Pos()}
+	for i, elt := range lit.Elts {
+		field := "Name"
+		if i == 1 {
+			field = "Deprecated"
+		}
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			ident, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				return nil, false
+			}
+			field, elt = ident.Name, kv.Value
+		}
+		value := pkg.info.Types[elt].Value
+		switch {
+		case value == nil:
+			return nil, false
+		case field == "Name" && value.Kind() == constant.String:
+			alias.name = constant.StringVal(value)
+		case field == "Deprecated" && value.Kind() == constant.Bool:
+			alias.deprecated = constant.BoolVal(value)
+		default:
+			return nil, false
+		}
+	}
+	return alias, true
+}
+
+// returnedExpr returns the expression returned by a method whose body is a single return statement of one result. It
+// returns nil if the method is not declared in the package, or its body is not a single return statement.
+func returnedExpr(pkg *loadedPackage, method types.Object) ast.Expr {
+	decl := findFuncDecl(pkg, method)
+	if decl == nil || decl.Body == nil || len(decl.Body.List) != 1 {
+		return nil
+	}
+	ret, ok := decl.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return nil
+	}
+	return ret.Results[0]
+}
+
+// findFuncDecl finds the declaration of the function in the package. It returns nil if the function is declared in
+// another package.
+func findFuncDecl(pkg *loadedPackage, fn types.Object) *ast.FuncDecl {
+	for _, file := range pkg.files {
+		for _, d := range file.Decls {
+			if decl, ok := d.(*ast.FuncDecl); ok && pkg.info.Defs[decl.Name] == fn {
+				return decl
+			}
+		}
+	}
+	return nil
+}
+
+// embeddedIn checks if the module is the other module, or embedded in it directly or indirectly.
+func (m *staticModule) embeddedIn(other *staticModule) bool {
+	for ; m != nil; m = m.embedding.parentModule() {
+		if m == other {
+			return true
+		}
+	}
+	return false
+}
+
+// parentModule returns the parent module of the embedding, or nil if there is no embedding.
+func (e *staticEmbedding) parentModule() *staticModule {
+	if e == nil {
+		return nil
+	}
+	return e.parent
+}
+
+// overrideEmbeddedProviders removes the providers of the modules embedded in the module, directly or indirectly, which
+// have the same method names as the providers of the module, the same as reflectedModule in package alice.
+func (m *staticModule) overrideEmbeddedProviders() {
+	for _, instance := range m.instances {
+		m.overrideEmbeddedProvider(m.embedded, instance.method, map[*staticModule]bool{m: true})
+	}
+}
+
+// overrideEmbeddedProvider removes the provider with the method name from the embedded modules, unless it is
+// overridden by the modules in between already.
+func (m *staticModule) overrideEmbeddedProvider(embedded []*staticModule, name string, visited map[*staticModule]bool) {
+	for _, em := range embedded {
+		if visited[em] {
+			continue
+		}
+		visited[em] = true
+		if em.removeInstance(name) {
+			m.addBase(em)
+		} else {
+			m.overrideEmbeddedProvider(em.embedded, name, visited)
+		}
+	}
+}
+
+// addBase adds a base module if it is not added yet.
+func (m *staticModule) addBase(base *staticModule) {
+	for _, b := range m.bases {
+		if b == base {
+			return
+		}
+	}
+	m.bases = append(m.bases, base)
+}
+
+// removeInstance removes the instance provided by the method with the name from the module. It returns false if the
+// module doesn't provide such an instance.
+func (m *staticModule) removeInstance(method string) bool {
+	for i, instance := range m.instances {
+		if instance.method == method {
+			m.instances = append(m.instances[:i:i], m.instances[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// qualifiedName returns the name of an instance qualified by the namespace of the module.
+func (m *staticModule) qualifiedName(name string) string {
+	if m.namespace == "" {
+		return name
+	}
+	return m.namespace + "." + name
+}
+
+// id returns the id of the module, which is the package path and the module name.
+func (m *staticModule) id() string {
+	return m.tp.Obj().Pkg().Path() + "." + m.name
+}
+
+// instanceOfType returns the instance of the identical type, or the first instance assignable to the type. Fallback
+// instances are skipped. It returns nil if no such instance is found.
+func (m *staticModule) instanceOfType(t types.Type) *staticInstance {
+	var assignable *staticInstance
+	for _, instance := range m.instances {
+		if instance.fallback {
+			continue
+		}
+		if types.Identical(instance.tp, t) {
+			return instance
+		}
+		if assignable == nil && types.AssignableTo(instance.tp, t) {
+			assignable = instance
+		}
+	}
+	return assignable
+}
+
+// isProviderSignature checks if the signature is of a provider, which takes no parameter or a context.Context, and
+// returns 1 value or (T, error).
+func isProviderSignature(sig *types.Signature) bool {
+	return (sig.Params().Len() == 0 || takesContext(sig)) && (sig.Results().Len() == 1 || returnsError(sig))
+}
+
+// returnsError checks if the signature returns (T, error).
+func returnsError(sig *types.Signature) bool {
+	return sig.Results().Len() == 2 && types.Identical(sig.Results().At(1).Type(), types.Universe.Lookup("error").Type())
+}
+
+// takesContext checks if the signature takes a context.Context as its only parameter.
+func takesContext(sig *types.Signature) bool {
+	if sig.Params().Len() != 1 {
+		return false
+	}
+	named, ok := sig.Params().At(0).Type().(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
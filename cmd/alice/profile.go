@@ -0,0 +1,156 @@
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"github.com/magic003/alice"
+)
+
+// moduleProfiles returns the profiles of the module if it declares the AliceProfiles method in the package. The method
+// is evaluated statically, so it must return a slice literal of constant strings. It returns error if the method could
+// not be evaluated, or a profile is empty.
+func moduleProfiles(pkg *loadedPackage, named *types.Named) ([]string, error) {
+	sel := lookupHook(named, _AliceProfilesMethodName)
+	if sel == nil {
+		return nil, nil
+	}
+
+	profiles, ok := evalStrings(pkg, returnedExpr(pkg, sel.Obj()))
+	if !ok {
+		return nil, errorf(sel.Obj().Pos(), "method %s.%s could not be evaluated statically; return a slice literal "+
+			"of constant strings", named.Obj().Name(), _AliceProfilesMethodName)
+	}
+	if profile, ok := emptyProfile(profiles); ok {
+		return nil, errorf(sel.Obj().Pos(), "module %s: profile %q is empty", named.Obj().Name(), profile)
+	}
+	return profiles, nil
+}
+
+// applyProviderProfiles sets the profiles of the instances if the module declares the AliceProviderProfiles method in
+// the package. The method is evaluated statically, so it must return a map literal whose keys are constant strings and
+// whose values are slice literals of constant strings. It returns error if the method could not be evaluated, a
+// provider is not defined, or a profile is empty.
+func applyProviderProfiles(pkg *loadedPackage, named *types.Named, instances []*staticInstance) error {
+	sel := lookupHook(named, _AliceProviderProfilesMethodName)
+	if sel == nil {
+		return nil
+	}
+
+	name := named.Obj().Name()
+	invalid := errorf(sel.Obj().Pos(), "method %s.%s could not be evaluated statically; return a map literal of "+
+		"slice literals of constant strings", name, _AliceProviderProfilesMethodName)
+	lit, ok := returnedExpr(pkg, sel.Obj()).(*ast.CompositeLit)
+	if !ok {
+		return invalid
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return invalid
+		}
+		key := pkg.info.Types[kv.Key].Value
+		profiles, ok := evalStrings(pkg, kv.Value)
+		if key == nil || key.Kind() != constant.String || !ok {
+			return invalid
+		}
+		method := constant.StringVal(key)
+		instance := findStaticInstance(instances, method)
+		if instance == nil {
+			return errorf(kv.Key.Pos(), "profiled provider %s.%s is not defined", name, method)
+		}
+		if profile, ok := emptyProfile(profiles); ok {
+			return errorf(kv.Value.Pos(), "provider %s.%s: profile %q is empty", name, method, profile)
+		}
+		instance.profiles = profiles
+	}
+	return nil
+}
+
+// evalStrings evaluates a slice literal of constant strings.
+func evalStrings(pkg *loadedPackage, expr ast.Expr) ([]string, bool) {
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil, false
+	}
+	var values []string
+	for _, elt := range lit.Elts {
+		value := pkg.info.Types[elt].Value
+		if value == nil || value.Kind() != constant.String {
+			return nil, false
+		}
+		values = append(values, constant.StringVal(value))
+	}
+	return values, true
+}
+
+// emptyProfile returns the first profile which is empty, ignoring the "!" prefix.
+func emptyProfile(profiles []string) (string, bool) {
+	for _, profile := range profiles {
+		if strings.TrimPrefix(profile, "!") == "" {
+			return profile, true
+		}
+	}
+	return "", false
+}
+
+// profileSet is the set of active profiles, the same as package alice.
+type profileSet map[string]bool
+
+// newProfileSet creates the set of active profiles. alice.DefaultProfile is active if there is no profile.
+func newProfileSet(profiles []string) profileSet {
+	if len(profiles) == 0 {
+		profiles = []string{alice.DefaultProfile}
+	}
+	ps := make(profileSet)
+	for _, profile := range profiles {
+		ps[profile] = true
+	}
+	return ps
+}
+
+// matches checks if any of the profiles is active, or there is no profile at all.
+func (ps profileSet) matches(profiles []string) bool {
+	if len(profiles) == 0 {
+		return true
+	}
+	for _, profile := range profiles {
+		if name := strings.TrimPrefix(profile, "!"); name != profile {
+			if !ps[name] {
+				return true
+			}
+		} else if ps[profile] {
+			return true
+		}
+	}
+	return false
+}
+
+// removeInactiveProviders removes the instances whose profiles are not active.
+func (m *staticModule) removeInactiveProviders(ps profileSet) {
+	var instances []*staticInstance
+	for _, instance := range m.instances {
+		if ps.matches(instance.profiles) {
+			instances = append(instances, instance)
+		}
+	}
+	m.instances = instances
+}
+
+// profilesFlag is a flag of comma separated profiles.
+type profilesFlag []string
+
+func (f *profilesFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *profilesFlag) Set(value string) error {
+	for _, profile := range strings.Split(value, ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			*f = append(*f, profile)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package ambiguous
+
+import "github.com/magic003/alice"
+
+type Client interface {
+	Fetch(url string) string
+}
+
+type client struct{}
+
+func (c *client) Fetch(url string) string {
+	return ""
+}
+
+type AModule struct {
+	alice.BaseModule
+}
+
+func (m *AModule) ClientA() Client {
+	return &client{}
+}
+
+type BModule struct {
+	alice.BaseModule
+}
+
+func (m *BModule) ClientB() Client {
+	return &client{}
+}
+
+type CModule struct {
+	alice.BaseModule
+	Client Client `alice:""`
+}
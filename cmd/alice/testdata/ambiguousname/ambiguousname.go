@@ -0,0 +1,38 @@
+package ambiguousname
+
+import (
+	"github.com/magic003/alice"
+)
+
+type Client interface {
+	Fetch(url string) string
+}
+
+type PaymentsModule struct {
+	alice.BaseModule
+}
+
+func (m *PaymentsModule) AliceNamespace() string {
+	return "payments"
+}
+
+func (m *PaymentsModule) Client() Client {
+	return nil
+}
+
+type ShippingModule struct {
+	alice.BaseModule
+}
+
+func (m *ShippingModule) AliceNamespace() string {
+	return "shipping"
+}
+
+func (m *ShippingModule) Client() Client {
+	return nil
+}
+
+type CheckoutModule struct {
+	alice.BaseModule
+	Client Client `alice:"Client"`
+}
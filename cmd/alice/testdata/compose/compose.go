@@ -0,0 +1,34 @@
+package compose
+
+import (
+	"github.com/magic003/alice"
+	"github.com/magic003/alice/example/module"
+)
+
+type Logger interface {
+	Log(msg string)
+}
+
+type LoggingModule struct {
+	alice.BaseModule
+}
+
+func (m *LoggingModule) Logger() Logger {
+	return nil
+}
+
+type ServerModule struct {
+	alice.BaseModule
+	LoggingModule
+	*module.ConfigModule
+	Log   Logger `alice:""`
+	Table string `alice:"Table"`
+}
+
+func (m *ServerModule) Address() string {
+	return ":8080"
+}
+
+func (m *LoggingModule) AliceNamespace() string {
+	return "logging"
+}
@@ -0,0 +1,81 @@
+package composehooks
+
+import (
+	"github.com/magic003/alice"
+)
+
+type Client interface {
+	Fetch(url string) string
+}
+
+type MetricsSink interface {
+	Flush()
+}
+
+type VendorModule struct {
+	alice.BaseModule
+}
+
+func (m *VendorModule) AliceNames() map[string]string {
+	return map[string]string{
+		"HTTPClient": "VendorClient",
+	}
+}
+
+func (m *VendorModule) AliceAliases() map[string][]alice.Alias {
+	return map[string][]alice.Alias{
+		"HTTPClient": {{Name: "Client"}},
+	}
+}
+
+func (m *VendorModule) AliceProviderProfiles() map[string][]string {
+	return map[string][]string{
+		"FakeClient": {"dev"},
+	}
+}
+
+func (m *VendorModule) AliceConditions() map[string][]alice.Condition {
+	return map[string][]alice.Condition{
+		"DefaultSink": {alice.OnMissingName("Sink")},
+	}
+}
+
+func (m *VendorModule) AliceFallbacks() []string {
+	return []string{"DefaultSink"}
+}
+
+func (m *VendorModule) HTTPClient() Client {
+	return nil
+}
+
+func (m *VendorModule) FakeClient() Client {
+	return nil
+}
+
+func (m *VendorModule) DefaultSink() MetricsSink {
+	return nil
+}
+
+type ProdModule struct {
+	alice.BaseModule
+}
+
+func (m *ProdModule) AliceProfiles() []string {
+	return []string{"prod"}
+}
+
+func (m *ProdModule) Database() string {
+	return "mysql"
+}
+
+// ServerModule embeds modules with hooks, which apply to the providers of the embedded modules only.
+type ServerModule struct {
+	alice.BaseModule
+	VendorModule
+	ProdModule
+	Client Client `alice:"Client"`
+}
+
+func (m *ServerModule) Address() string {
+	return ":8080"
+}
@@ -0,0 +1,47 @@
+package condition
+
+import (
+	"reflect"
+
+	"github.com/magic003/alice"
+)
+
+type Client interface {
+	Fetch(url string) string
+}
+
+type MetricsSink interface {
+	Flush()
+}
+
+type DefaultsModule struct {
+	alice.BaseModule
+}
+
+func (m *DefaultsModule) AliceConditions() map[string][]alice.Condition {
+	return map[string][]alice.Condition{
+		"DefaultClient": {alice.OnMissingType(reflect.TypeOf((*Client)(nil)).Elem())},
+		"MetricsSink":   {alice.OnPresentName("Registry")},
+	}
+}
+
+func (m *DefaultsModule) DefaultClient() Client {
+	return nil
+}
+
+func (m *DefaultsModule) MetricsSink() MetricsSink {
+	return nil
+}
+
+type AppModule struct {
+	alice.BaseModule
+}
+
+func (m *AppModule) Client() Client {
+	return nil
+}
+
+type ServiceModule struct {
+	alice.BaseModule
+	Client Client `alice:""`
+}
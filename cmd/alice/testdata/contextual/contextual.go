@@ -0,0 +1,26 @@
+package contextual
+
+import (
+	"context"
+
+	"github.com/magic003/alice"
+)
+
+type DB struct{}
+
+type PersistModule struct {
+	alice.BaseModule
+}
+
+func (m *PersistModule) DSN() string {
+	return "memory"
+}
+
+func (m *PersistModule) DB(ctx context.Context) *DB {
+	return &DB{}
+}
+
+type ServiceModule struct {
+	alice.BaseModule
+	DB *DB `alice:""`
+}
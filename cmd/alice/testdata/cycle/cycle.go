@@ -0,0 +1,21 @@
+package cycle
+
+import "github.com/magic003/alice"
+
+type AModule struct {
+	alice.BaseModule
+	B string `alice:"B"`
+}
+
+func (m *AModule) A() string {
+	return "a"
+}
+
+type BModule struct {
+	alice.BaseModule
+	A string `alice:"A"`
+}
+
+func (m *BModule) B() string {
+	return "b"
+}
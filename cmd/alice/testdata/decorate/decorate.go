@@ -0,0 +1,59 @@
+package decorate
+
+import (
+	"github.com/magic003/alice"
+)
+
+type Client interface {
+	Fetch(url string) string
+}
+
+type ClientModule struct {
+	alice.BaseModule
+}
+
+func (m *ClientModule) Client() Client {
+	return nil
+}
+
+type ConfigModule struct {
+	alice.BaseModule
+}
+
+func (m *ConfigModule) Retries() int {
+	return 3
+}
+
+type RetryModule struct {
+	alice.BaseModule
+	Retries int `alice:"Retries"`
+}
+
+func (m *RetryModule) AliceDecorators() map[string]string {
+	return map[string]string{
+		"WithRetries": "Client",
+	}
+}
+
+func (m *RetryModule) WithRetries(client Client) Client {
+	return client
+}
+
+type MetricsModule struct {
+	alice.BaseModule
+}
+
+func (m *MetricsModule) AliceDecorators() map[string]string {
+	return map[string]string{
+		"WithMetrics": "Client",
+	}
+}
+
+func (m *MetricsModule) WithMetrics(client Client) Client {
+	return client
+}
+
+type ServiceModule struct {
+	alice.BaseModule
+	Client Client `alice:""`
+}
@@ -0,0 +1,27 @@
+package embedvalue
+
+import (
+	"github.com/magic003/alice"
+)
+
+type Logger interface {
+	Log(msg string)
+}
+
+type LoggingModule struct {
+	alice.BaseModule
+}
+
+func (m *LoggingModule) Logger() Logger {
+	return nil
+}
+
+type ServerModule struct {
+	alice.BaseModule
+	LoggingModule
+}
+
+type AdminModule struct {
+	alice.BaseModule
+	LoggingModule
+}
@@ -0,0 +1,92 @@
+package fallback
+
+import (
+	"github.com/magic003/alice"
+)
+
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+type TeamLogger struct{}
+
+func (l *TeamLogger) Printf(format string, args ...interface{}) {}
+
+type StdLogger struct{}
+
+func (l *StdLogger) Printf(format string, args ...interface{}) {}
+
+type LoggingModule struct {
+	alice.BaseModule
+}
+
+func (m *LoggingModule) AliceFallbacks() []string {
+	return []string{"Logger", "StdLogger", "Timeout"}
+}
+
+func (m *LoggingModule) Logger() Logger {
+	return nil
+}
+
+func (m *LoggingModule) StdLogger() *StdLogger {
+	return &StdLogger{}
+}
+
+func (m *LoggingModule) Timeout() int {
+	return 30
+}
+
+type TeamModule struct {
+	alice.BaseModule
+}
+
+func (m *TeamModule) TeamLogger() *TeamLogger {
+	return &TeamLogger{}
+}
+
+func (m *TeamModule) Retries() int {
+	return 3
+}
+
+type ServiceModule struct {
+	alice.BaseModule
+	Logger  Logger `alice:""`
+	Timeout int    `alice:"Timeout"`
+}
+
+type PaymentsClient struct{}
+
+type OrdersClient struct{}
+
+type PaymentsModule struct {
+	alice.BaseModule
+}
+
+func (m *PaymentsModule) AliceNamespace() string {
+	return "payments"
+}
+
+func (m *PaymentsModule) AliceFallbacks() []string {
+	return []string{"Client"}
+}
+
+func (m *PaymentsModule) Client() *PaymentsClient {
+	return &PaymentsClient{}
+}
+
+type OrdersModule struct {
+	alice.BaseModule
+}
+
+func (m *OrdersModule) AliceNamespace() string {
+	return "orders"
+}
+
+func (m *OrdersModule) Client() *OrdersClient {
+	return &OrdersClient{}
+}
+
+type CheckoutModule struct {
+	alice.BaseModule
+	Client *PaymentsClient `alice:"payments.Client"`
+}
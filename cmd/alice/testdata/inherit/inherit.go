@@ -0,0 +1,44 @@
+package inherit
+
+import (
+	"github.com/magic003/alice"
+)
+
+type Client interface {
+	Fetch(url string) string
+}
+
+type VendorModule struct {
+	alice.BaseModule
+	Timeout int `alice:"Timeout"`
+}
+
+func (m *VendorModule) Client() Client {
+	return nil
+}
+
+func (m *VendorModule) Retries() int {
+	return 3
+}
+
+type CustomizedModule struct {
+	alice.BaseModule
+	VendorModule
+}
+
+func (m *CustomizedModule) Client() Client {
+	return m.VendorModule.Client()
+}
+
+type ConfigModule struct {
+	alice.BaseModule
+}
+
+func (m *ConfigModule) Timeout() int {
+	return 10
+}
+
+type ServiceModule struct {
+	alice.BaseModule
+	Client Client `alice:""`
+}
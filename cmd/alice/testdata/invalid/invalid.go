@@ -0,0 +1,11 @@
+package invalid
+
+import "github.com/magic003/alice"
+
+type InvalidModule struct {
+	alice.BaseModule
+}
+
+func (m *InvalidModule) Foo(name string) string {
+	return name
+}
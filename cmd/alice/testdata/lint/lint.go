@@ -0,0 +1,107 @@
+package lint
+
+import "github.com/magic003/alice"
+
+type Client interface {
+	Fetch(url string) string
+}
+
+type LintModule struct {
+	alice.BaseModule
+	unexported Client `alice:""`
+	Missing    Client `alice:"Missinq"`
+	Invalid    Client `alice:"HTTP Client"`
+	Misspelled Client `alcie:"Client"`
+	Malformed  Client `alice: "Client"`
+	Valid      Client `json:"valid" alice:"Client"`
+	Alias      string `alias:"name"`
+}
+
+func (m LintModule) Client() Client {
+	return nil
+}
+
+func (m *LintModule) Format(url string) string {
+	return url
+}
+
+func (m *LintModule) helper(url string) string {
+	return url
+}
+
+type Helper struct{}
+
+func (h *Helper) Join(a, b string) string {
+	return a + b
+}
+
+type HelperModule struct {
+	alice.BaseModule
+	Helper
+}
+
+func (m *HelperModule) AliceIgnore() []string {
+	return []string{"Escape", "Unknown"}
+}
+
+func (m *HelperModule) Escape(url string) string {
+	return url
+}
+
+type PaymentsModule struct {
+	alice.BaseModule
+}
+
+func (m *PaymentsModule) AliceNamespace() string {
+	return "payments"
+}
+
+func (m *PaymentsModule) Gateway() Client {
+	return nil
+}
+
+type CheckoutModule struct {
+	alice.BaseModule
+	Gateway Client `alice:"payments.Gateway"`
+	Missing Client `alice:"payments.Client"`
+}
+
+type RenamedModule struct {
+	alice.BaseModule
+}
+
+func (m *RenamedModule) AliceAliases() map[string][]alice.Alias {
+	return map[string][]alice.Alias{
+		"Fetcher": {{Name: "OldFetcher", Deprecated: true}, {"Getter", false}},
+	}
+}
+
+func (m *RenamedModule) Fetcher() Client {
+	return nil
+}
+
+type FetchingModule struct {
+	alice.BaseModule
+	Old    Client `alice:"OldFetcher"`
+	Getter Client `alice:"Getter"`
+}
+
+type HTTPModule struct {
+	alice.BaseModule
+}
+
+func (m *HTTPModule) AliceNames() map[string]string {
+	return map[string]string{
+		"HTTPClient": "http-client",
+	}
+}
+
+func (m *HTTPModule) HTTPClient() Client {
+	return nil
+}
+
+type HTTPUserModule struct {
+	alice.BaseModule
+	Client Client `alice:"http-client"`
+	Method Client `alice:"HTTPClient"`
+}
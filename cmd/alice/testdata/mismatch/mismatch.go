@@ -0,0 +1,16 @@
+package mismatch
+
+import "github.com/magic003/alice"
+
+type ConfigModule struct {
+	alice.BaseModule
+}
+
+func (m *ConfigModule) Retries() string {
+	return "3"
+}
+
+type ClientModule struct {
+	alice.BaseModule
+	Retries int `alice:"Retries"`
+}
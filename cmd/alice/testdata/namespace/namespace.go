@@ -0,0 +1,60 @@
+package namespace
+
+import (
+	"github.com/magic003/alice"
+)
+
+type Client interface {
+	Fetch(url string) string
+}
+
+type PaymentsModule struct {
+	alice.BaseModule
+}
+
+func (m *PaymentsModule) AliceNamespace() string {
+	return "payments"
+}
+
+func (m *PaymentsModule) AliceAliases() map[string][]alice.Alias {
+	return map[string][]alice.Alias{
+		"Client": {{Name: "Gateway", Deprecated: true}},
+	}
+}
+
+func (m *PaymentsModule) Client() Client {
+	return nil
+}
+
+type ShippingModule struct {
+	alice.BaseModule
+}
+
+func (m *ShippingModule) AliceNamespace() string {
+	return "shipping"
+}
+
+func (m *ShippingModule) Client() Client {
+	return nil
+}
+
+type TrackingModule struct {
+	alice.BaseModule
+}
+
+func (m *TrackingModule) AliceNames() map[string]string {
+	return map[string]string{
+		"Client": "tracking-client",
+	}
+}
+
+func (m *TrackingModule) Client() Client {
+	return nil
+}
+
+type CheckoutModule struct {
+	alice.BaseModule
+	Payments Client `alice:"payments.Client"`
+	Shipping Client `alice:"shipping.Client"`
+	Tracking Client `alice:"tracking-client"`
+}
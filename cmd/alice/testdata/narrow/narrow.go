@@ -0,0 +1,26 @@
+package narrow
+
+import "github.com/magic003/alice"
+
+type Client interface {
+	Fetch(url string) string
+}
+
+type HTTPClient struct{}
+
+func (c *HTTPClient) Fetch(url string) string {
+	return ""
+}
+
+type ClientModule struct {
+	alice.BaseModule
+}
+
+func (m *ClientModule) Client() Client {
+	return &HTTPClient{}
+}
+
+type CrawlerModule struct {
+	alice.BaseModule
+	Client *HTTPClient `alice:"Client"`
+}
@@ -0,0 +1,62 @@
+package profile
+
+import (
+	"github.com/magic003/alice"
+)
+
+type DAO interface {
+	Get(key string) string
+}
+
+type Client interface {
+	Fetch(url string) string
+}
+
+type InMemoryDAOModule struct {
+	alice.BaseModule
+}
+
+func (m *InMemoryDAOModule) AliceProfiles() []string {
+	return []string{"!prod"}
+}
+
+func (m *InMemoryDAOModule) DAO() DAO {
+	return nil
+}
+
+type MySQLDAOModule struct {
+	alice.BaseModule
+	DSN string `alice:"DSN"`
+}
+
+func (m *MySQLDAOModule) AliceProfiles() []string {
+	return []string{"prod"}
+}
+
+func (m *MySQLDAOModule) DAO() DAO {
+	return nil
+}
+
+type ConfigModule struct {
+	alice.BaseModule
+}
+
+func (m *ConfigModule) AliceProviderProfiles() map[string][]string {
+	return map[string][]string{
+		"DSN":        {"prod"},
+		"FakeClient": {"dev"},
+	}
+}
+
+func (m *ConfigModule) DSN() string {
+	return "user@tcp(localhost:3306)/db"
+}
+
+func (m *ConfigModule) FakeClient() Client {
+	return nil
+}
+
+type ServiceModule struct {
+	alice.BaseModule
+	DAO DAO `alice:"DAO"`
+}
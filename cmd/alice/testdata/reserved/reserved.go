@@ -0,0 +1,11 @@
+package reserved
+
+import "github.com/magic003/alice"
+
+type ReservedModule struct {
+	alice.BaseModule
+}
+
+func (m *ReservedModule) Add() int {
+	return 1
+}
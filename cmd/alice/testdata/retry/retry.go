@@ -0,0 +1,28 @@
+package retry
+
+import (
+	"time"
+
+	"github.com/magic003/alice"
+)
+
+type Broker struct{}
+
+type MessagingModule struct {
+	alice.BaseModule
+}
+
+func (m *MessagingModule) AliceRetryPolicies() map[string]alice.RetryPolicy {
+	return map[string]alice.RetryPolicy{
+		"Broker": {Attempts: 3, Backoff: time.Second},
+	}
+}
+
+func (m *MessagingModule) Broker() (*Broker, error) {
+	return &Broker{}, nil
+}
+
+type ServiceModule struct {
+	alice.BaseModule
+	Broker *Broker `alice:""`
+}
@@ -0,0 +1,201 @@
+package alice
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const _AliceConditionsMethodName = "AliceConditions"
+
+// conditionKind is the kind of a Condition.
+type conditionKind int
+
+const (
+	conditionOnMissingType conditionKind = iota
+	conditionOnPresentType
+	conditionOnMissingName
+	conditionOnPresentName
+)
+
+// Condition decides whether a provider is kept, depending on the other providers. It is created by OnMissingType,
+// OnPresentType, OnMissingName or OnPresentName.
+type Condition struct {
+	kind conditionKind
+	tp   reflect.Type
+	name string
+}
+
+// OnMissingType keeps the provider only if no other provider provides an instance assignable to the type.
+func OnMissingType(t reflect.Type) Condition {
+	return Condition{kind: conditionOnMissingType, tp: t}
+}
+
+// OnPresentType keeps the provider only if another provider provides an instance assignable to the type.
+func OnPresentType(t reflect.Type) Condition {
+	return Condition{kind: conditionOnPresentType, tp: t}
+}
+
+// OnMissingName keeps the provider only if no other provider provides an instance with the name. The name is either
+// qualified or unqualified, and matches aliases as well.
+func OnMissingName(name string) Condition {
+	return Condition{kind: conditionOnMissingName, name: name}
+}
+
+// OnPresentName keeps the provider only if another provider provides an instance with the name. The name is either
+// qualified or unqualified, and matches aliases as well.
+func OnPresentName(name string) Condition {
+	return Condition{kind: conditionOnPresentName, name: name}
+}
+
+// String returns the condition as it is created, e.g. OnMissingName(HTTPClient).
+func (c Condition) String() string {
+	switch c.kind {
+	case conditionOnMissingType:
+		return fmt.Sprintf("OnMissingType(%v)", c.tp)
+	case conditionOnPresentType:
+		return fmt.Sprintf("OnPresentType(%v)", c.tp)
+	case conditionOnMissingName:
+		return fmt.Sprintf("OnMissingName(%s)", c.name)
+	default:
+		return fmt.Sprintf("OnPresentName(%s)", c.name)
+	}
+}
+
+// ConditionalModule is implemented by modules whose providers are kept only on conditions, e.g. shared modules
+// providing defaults which the application could replace by providing its own instances.
+//
+//	func (m *DefaultsModule) AliceConditions() map[string][]alice.Condition {
+//		return map[string][]alice.Condition{
+//			"HTTPClient":  {alice.OnMissingType(reflect.TypeOf((*http.Client)(nil)))},
+//			"MetricsSink": {alice.OnPresentName("Registry")},
+//		}
+//	}
+//
+// A provider is kept if all of its conditions hold. The conditions are evaluated against the unconditional providers
+// and the conditional providers kept already, in the order of modules and providers, after profiles and overrides are
+// applied. Dropped providers are neither instantiated nor reported as duplicated.
+type ConditionalModule interface {
+	Module
+	// AliceConditions returns the conditions of the providers, keyed by the provider method names.
+	AliceConditions() map[string][]Condition
+}
+
+// applyProviderConditions sets the conditions of the instances if the module implements ConditionalModule. It returns
+// error if a provider is not defined, or a condition is invalid.
+func applyProviderConditions(m Module, instances []*instanceMethod) error {
+	cm, ok := m.(ConditionalModule)
+	if !ok || !declaresHook(m, _AliceConditionsMethodName) {
+		return nil
+	}
+
+	name := reflect.TypeOf(m).Elem().Name()
+	for method, conditions := range cm.AliceConditions() {
+		instance := findInstance(instances, method)
+		if instance == nil {
+			return fmt.Errorf("conditional provider %s.%s is not defined", name, method)
+		}
+		for _, c := range conditions {
+			switch c.kind {
+			case conditionOnMissingType, conditionOnPresentType:
+				if c.tp == nil {
+					return fmt.Errorf("condition %s of %s.%s has no type", c, name, method)
+				}
+			default:
+				if c.name == "" {
+					return fmt.Errorf("condition %s of %s.%s has no name", c, name, method)
+				}
+			}
+		}
+		instance.conditions = conditions
+	}
+	return nil
+}
+
+// providedInstance is an instance and the module providing it.
+type providedInstance struct {
+	rm       *reflectedModule
+	instance *instanceMethod
+}
+
+// hasName checks if the instance has the name, qualified or not, or an alias of the name.
+func (p *providedInstance) hasName(name string) bool {
+	if name == p.rm.qualifiedName(p.instance.name) || name == p.instance.name {
+		return true
+	}
+	for _, alias := range p.instance.aliases {
+		if name == p.rm.qualifiedName(alias.Name) || name == alias.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasQualifiedName checks if the qualified name of the instance, or of an alias, is the name.
+func (p *providedInstance) hasQualifiedName(name string) bool {
+	if name == p.rm.qualifiedName(p.instance.name) {
+		return true
+	}
+	for _, alias := range p.instance.aliases {
+		if name == p.rm.qualifiedName(alias.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// holds checks if the condition holds against the provided instances.
+func (c Condition) holds(provided []*providedInstance) bool {
+	present := false
+	for _, p := range provided {
+		switch c.kind {
+		case conditionOnMissingType, conditionOnPresentType:
+			present = p.instance.tp.AssignableTo(c.tp)
+		default:
+			present = p.hasName(c.name)
+		}
+		if present {
+			break
+		}
+	}
+	if c.kind == conditionOnMissingType || c.kind == conditionOnMissingName {
+		return !present
+	}
+	return present
+}
+
+// applyConditions removes the conditional instances whose conditions don't hold.
+func applyConditions(rms []*reflectedModule) {
+	var provided []*providedInstance
+	for _, rm := range rms {
+		for _, instance := range rm.instances {
+			if len(instance.conditions) == 0 {
+				provided = append(provided, &providedInstance{rm: rm, instance: instance})
+			}
+		}
+	}
+
+	for _, rm := range rms {
+		var instances []*instanceMethod
+		for _, instance := range rm.instances {
+			if len(instance.conditions) == 0 {
+				instances = append(instances, instance)
+				continue
+			}
+			if conditionsHold(instance.conditions, provided) {
+				instances = append(instances, instance)
+				provided = append(provided, &providedInstance{rm: rm, instance: instance})
+			}
+		}
+		rm.instances = instances
+	}
+}
+
+// conditionsHold checks if all of the conditions hold against the provided instances.
+func conditionsHold(conditions []Condition, provided []*providedInstance) bool {
+	for _, c := range conditions {
+		if !c.holds(provided) {
+			return false
+		}
+	}
+	return true
+}
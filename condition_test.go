@@ -0,0 +1,133 @@
+package alice
+
+import (
+	"reflect"
+	"testing"
+)
+
+type DefaultsModule struct {
+	BaseModule
+}
+
+func (m *DefaultsModule) AliceConditions() map[string][]Condition {
+	return map[string][]Condition{
+		"DefaultD1": {OnMissingType(reflect.TypeOf((*D1)(nil)).Elem())},
+		"D2":        {OnMissingName("D2")},
+		"D3":        {OnPresentType(reflect.TypeOf((*D1)(nil)).Elem()), OnPresentName("Registry")},
+	}
+}
+
+func (m *DefaultsModule) DefaultD1() D1 {
+	return &decoratedD1{}
+}
+
+func (m *DefaultsModule) D2() D2 {
+	return &D2Impl{}
+}
+
+func (m *DefaultsModule) D3() D3 {
+	return &D3Impl{}
+}
+
+type DefaultsDependantModule struct {
+	BaseModule
+	D1 D1 `alice:""`
+	D2 D2 `alice:""`
+}
+
+type DefaultsServerModule struct {
+	BaseModule
+	*DefaultsModule
+}
+
+func (m *DefaultsServerModule) Address() string {
+	return ":8080"
+}
+
+type undefinedConditionModule struct {
+	BaseModule
+}
+
+func (m *undefinedConditionModule) AliceConditions() map[string][]Condition {
+	return map[string][]Condition{
+		"D1": {OnMissingName("D1")},
+	}
+}
+
+type invalidConditionModule struct {
+	BaseModule
+}
+
+func (m *invalidConditionModule) AliceConditions() map[string][]Condition {
+	return map[string][]Condition{
+		"D1": {OnMissingType(nil)},
+	}
+}
+
+func (m *invalidConditionModule) D1() D1 {
+	return &D1Impl{}
+}
+
+func TestConditions_Defaults(t *testing.T) {
+	dependant := &DefaultsDependantModule{}
+	c, err := NewContainer(&DefaultsModule{}, dependant)
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(dependant.D1, &decoratedD1{}) {
+		t.Errorf("bad default D1: got %v, expected %v", dependant.D1, &decoratedD1{})
+	}
+	if !reflect.DeepEqual(dependant.D2, &D2Impl{}) {
+		t.Errorf("bad default D2: got %v, expected %v", dependant.D2, &D2Impl{})
+	}
+	if d3, ok := c.(*container).instanceByName["D3"]; ok {
+		t.Errorf("bad D3 without D1 and Registry: got %v, expected none", d3)
+	}
+}
+
+func TestConditions_Replaced(t *testing.T) {
+	dependant := &DefaultsDependantModule{}
+	c, err := NewContainerWithOptions([]Module{&DefaultsModule{}, &M1{}, dependant}, WithInstance("Registry", "registry"))
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(dependant.D1, &D1Impl{}) {
+		t.Errorf("bad D1 replacing the default: got %v, expected %v", dependant.D1, &D1Impl{})
+	}
+	if d2 := c.InstanceByName("D2"); d2 != dependant.D2 {
+		t.Errorf("bad D2 replacing the default: got %v, expected %v", d2, dependant.D2)
+	}
+	if d3 := c.InstanceByName("D3"); !reflect.DeepEqual(d3, &D3Impl{}) {
+		t.Errorf("bad D3 on present D1 and Registry: got %v, expected %v", d3, &D3Impl{})
+	}
+}
+
+func TestConditions_EmbeddedModule(t *testing.T) {
+	dependant := &DefaultsDependantModule{}
+	_, err := NewContainer(&DefaultsServerModule{DefaultsModule: &DefaultsModule{}}, dependant)
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(dependant.D1, &decoratedD1{}) {
+		t.Errorf("bad default D1: got %v, expected %v", dependant.D1, &decoratedD1{})
+	}
+}
+
+func TestConditions_UndefinedProvider(t *testing.T) {
+	_, err := reflectModule(&undefinedConditionModule{})
+	if err == nil {
+		t.Error("expect error after reflectModule() on conditions of undefined provider")
+	}
+	t.Log(err.Error())
+}
+
+func TestConditions_Invalid(t *testing.T) {
+	_, err := reflectModule(&invalidConditionModule{})
+	if err == nil {
+		t.Error("expect error after reflectModule() on condition without type")
+	}
+	t.Log(err.Error())
+}
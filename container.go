@@ -2,20 +2,48 @@
 package alice
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"time"
 )
 
 // CreateContainer creates a new instance of container with specified modules. It panics if any of the module is
-// invalid. This is the only way to create a container. Most applications call it only once during bootstrap.
+// invalid. Most applications call it only once during bootstrap.
 func CreateContainer(modules ...Module) Container {
-	c := &container{
-		modules: modules,
-	}
+	c := newContainer(modules, nil)
 	c.populate()
 	return c
 }
 
+// NewContainer creates a new instance of container with specified modules. It is the same as CreateContainer,
+// except that it returns error instead of panicking if any of the module is invalid.
+func NewContainer(modules ...Module) (Container, error) {
+	return NewContainerWithOptions(modules)
+}
+
+// NewContainerWithOptions creates a new instance of container with specified modules, the same as NewContainer,
+// except that the options customize how the container is built.
+func NewContainerWithOptions(modules []Module, opts ...Option) (Container, error) {
+	c := newContainer(modules, opts)
+	if err := c.build(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newContainer creates a container of the modules with the options applied, without building it.
+func newContainer(modules []Module, opts []Option) *container {
+	o := applyOptions(opts)
+	return &container{
+		ctx:     o.ctx,
+		modules: modules,
+		opts:    o,
+	}
+}
+
 // Container defines the interface of an instance container. It initializes instances based on dependencies,
 // and provides APIs to retrieve instances by type or name.
 type Container interface {
@@ -24,90 +52,377 @@ type Container interface {
 	Instance(t reflect.Type) interface{}
 	// InstanceByName returns an instance by name. It panics when no instance is found.
 	InstanceByName(name string) interface{}
+	// Overrides returns the overrides applied when building the container.
+	Overrides() []Override
+	// BuildReport returns the wall time spent in each provider and module while building the container, and the
+	// critical path bounding the startup time.
+	BuildReport() *BuildReport
+	// Close closes the instances implementing io.Closer in reverse order of being built, then notifies the listeners
+	// registered by WithListener. The instances passed by WithInstance or WithOverride are not closed. It returns the
+	// errors of closing the instances joined.
+	Close() error
 }
 
 // container is an implementation of Container interface. It is not thread-safe.
 type container struct {
+	// ctx is passed to the providers taking a context.Context. It is nil unless set by WithContext.
+	ctx     context.Context
 	modules []Module
 
 	instanceByName map[string]interface{}
 	instanceByType map[reflect.Type][]interface{}
+	// qualifiedNames maps unqualified names to the qualified names of instances and aliases in namespaces.
+	qualifiedNames map[string][]string
+	aliases        map[string]*aliasTarget
+	// fallbacks are the fallback instances in the order of modules and providers. They are not in instanceByType, and
+	// are found by type only if no other instance matches the type.
+	fallbacks []*fallbackInstance
+	overrides []Override
+	opts      *options
+	report    *BuildReport
+	// closers are the instances implementing io.Closer in the order their providers return, to be closed if the build
+	// fails or the container is closed. Instances supplied by the caller are not included.
+	closers []io.Closer
 }
 
 func (c *container) Instance(t reflect.Type) interface{} {
-	return c.findInstanceByType(t)
+	instance, err := c.findInstanceByType(t)
+	if err != nil {
+		panic(err.Error())
+	}
+	return instance
 }
 
 func (c *container) InstanceByName(name string) interface{} {
-	return c.findInstanceByName(name)
+	instance, err := c.findInstanceByName(name)
+	if err != nil {
+		panic(err.Error())
+	}
+	return instance
+}
+
+func (c *container) Overrides() []Override {
+	return c.overrides
+}
+
+func (c *container) BuildReport() *BuildReport {
+	return c.report
+}
+
+func (c *container) Close() error {
+	var errs []error
+	for i := len(c.closers) - 1; i >= 0; i-- {
+		if err := c.closers[i].Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	c.closers = nil
+	c.opts.emit(Event{Kind: ContainerClosed})
+	return errors.Join(errs...)
 }
 
 func (c *container) populate() {
-	rms := c.reflectModules(c.modules)
-	g, err := createGraph(rms...)
-	if err != nil {
+	if err := c.build(); err != nil {
 		panic(err)
 	}
+}
 
-	orderedRms, err := g.instantiationOrder()
+// build instantiates the modules in dependency order. It returns error if any of the module is invalid. If it fails
+// after calling providers, the instances built already and implementing io.Closer are closed.
+func (c *container) build() error {
+	start := time.Now()
+	if c.opts == nil {
+		c.opts = defaultOptions()
+	}
+	p, err := plan(c.modules, c.opts)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	c.overrides = p.overrides
 
 	c.instanceByName = make(map[string]interface{})
 	c.instanceByType = make(map[reflect.Type][]interface{})
-	for _, rm := range orderedRms {
-		c.instantiateModule(rm)
+	c.qualifiedNames = make(map[string][]string)
+	c.aliases = make(map[string]*aliasTarget)
+	for _, rm := range p.graph.modules {
+		for _, instance := range rm.instances {
+			if instance.fallback {
+				name := rm.qualifiedName(instance.name)
+				c.fallbacks = append(c.fallbacks, &fallbackInstance{name: name, tp: instance.tp})
+			}
+		}
 	}
+	order := make([]string, 0, len(p.order))
+	for _, rm := range p.order {
+		c.opts.emit(Event{Kind: ModuleReflected, Module: rm.name})
+		order = append(order, rm.name)
+	}
+	c.opts.emit(Event{Kind: GraphBuilt, Order: order})
+
+	c.report = &BuildReport{Providers: []*ProviderTiming{}, Modules: []*ModuleTiming{}}
+	durations := make(map[*reflectedModule]time.Duration)
+	for _, rm := range p.order {
+		moduleStart := time.Now()
+		if err := c.instantiateModule(rm, p.graph); err != nil {
+			var panicErr *PanicError
+			if errors.As(err, &panicErr) {
+				panicErr.Path = dependencyPath(p.graph, p.order, rm)
+			}
+			closeInstances(c.closers)
+			return err
+		}
+		durations[rm] = time.Since(moduleStart)
+		c.report.Modules = append(c.report.Modules, &ModuleTiming{Module: rm.name, Duration: durations[rm]})
+	}
+	c.report.computeCriticalPath(p.graph, p.order, durations)
+	c.report.Total = time.Since(start)
+	return nil
 }
 
-func (c *container) instantiateModule(rm *reflectedModule) {
+func (c *container) instantiateModule(rm *reflectedModule, g *graph) error {
 	for _, dep := range rm.namedDepends {
-		instance := c.findInstanceByName(dep.name)
-		dep.field.Set(reflect.ValueOf(instance))
+		instance, target, err := c.lookupName(dep.name)
+		if err != nil {
+			return err
+		}
+		if target != nil && target.deprecated {
+			c.opts.warnDeprecated("dependency name", rm.name+"."+dep.name, target.name)
+		}
+		v := reflect.ValueOf(instance)
+		if v.IsValid() && !v.Type().AssignableTo(dep.field.Type()) {
+			return fmt.Errorf("dependency name %s.%s of type %s is not assignable from instance %s of type %s",
+				rm.name, dep.name, dep.field.Type(), dep.name, v.Type())
+		}
+		dep.field.Set(v)
+		c.opts.debug("alice: dependency assigned", "module", rm.name, "field", dep.fieldName, "name", dep.name)
 	}
 	for _, dep := range rm.typedDepends {
-		instance := c.findInstanceByType(dep.tp)
+		instance, err := c.findInstanceByType(dep.tp)
+		if err != nil {
+			return err
+		}
 		dep.field.Set(reflect.ValueOf(instance))
+		edge := g.edgeOf(rm, dep.fieldName)
+		c.opts.debug("alice: dependency assigned", "module", rm.name, "field", dep.fieldName, "type", dep.tp,
+			"name", edge.provider.qualifiedName(edge.instance.name))
 	}
 
 	for _, instanceMethod := range rm.instances {
-		instance := instanceMethod.method.Call(nil)[0].Interface()
+		if err := c.checkContext(rm, instanceMethod, false); err != nil {
+			return err
+		}
+		name := rm.qualifiedName(instanceMethod.name)
+		start := time.Now()
+		instance, attempts, err := c.callProvider(rm, instanceMethod, name)
+		if err != nil {
+			return err
+		}
+		c.report.Providers = append(c.report.Providers, &ProviderTiming{
+			Module:   rm.name,
+			Method:   instanceMethod.methodName,
+			Instance: name,
+			Duration: time.Since(start),
+			Attempts: attempts,
+		})
+		c.addInstance(name, instanceMethod.tp, instance)
+		c.opts.emit(Event{Kind: InstancePublished, Module: rm.name, Instance: name, Type: instanceMethod.tp})
+		for _, alias := range instanceMethod.aliases {
+			c.addAlias(rm.qualifiedName(alias.Name), name, alias.Deprecated)
+		}
+	}
+	return nil
+}
 
-		c.instanceByName[instanceMethod.name] = instance
+// callProvider calls the provider, and calls it again after a backoff if it fails with an error retryable by its
+// policy. Each attempt is reported to the listeners. It returns the number of attempts.
+func (c *container) callProvider(rm *reflectedModule, im *instanceMethod, name string) (interface{}, int, error) {
+	for attempt := 1; ; attempt++ {
+		c.opts.emit(Event{Kind: ProviderCalling, Module: rm.name, Method: im.methodName, Instance: name,
+			Attempt: attempt})
+		start := time.Now()
+		instance, err := c.attemptProvider(rm, im, attempt)
+		c.opts.emit(Event{Kind: ProviderCalled, Module: rm.name, Method: im.methodName, Instance: name,
+			Attempt: attempt, Duration: time.Since(start), Err: err})
+		providerErr, ok := err.(*ProviderError)
+		if !ok || !im.retryPolicy.shouldRetry(providerErr.Err, attempt) {
+			return instance, attempt, err
+		}
+		if err := c.sleep(rm, im, im.retryPolicy.backoff(attempt)); err != nil {
+			return nil, attempt, err
+		}
+	}
+}
+
+// sleep waits before the next attempt of the provider. It returns error if the container is created with a context
+// which is done meanwhile.
+func (c *container) sleep(rm *reflectedModule, im *instanceMethod, d time.Duration) error {
+	if c.ctx == nil {
+		time.Sleep(d)
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-c.ctx.Done():
+		return checkContext(c.ctx, rm, im, false)
+	}
+}
 
-		typedInstances, _ := c.instanceByType[instanceMethod.tp]
+// attemptProvider calls the provider method with the context if it takes one, and checks the context is not done
+// after it returns. Then it runs the lifecycle hooks if enabled and the decorators on the instance. An error returned
+// by the provider is wrapped by *ProviderError, and a panic is recovered and returned as *PanicError.
+func (c *container) attemptProvider(rm *reflectedModule, im *instanceMethod, attempt int) (
+	instance interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			instance, err = nil, newPanicError(rm, im, r)
+		}
+	}()
+
+	var args []reflect.Value
+	if im.withContext {
+		ctx := c.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		args = []reflect.Value{reflect.ValueOf(ctx)}
+	}
+	results := im.method.Call(args)
+	// the instance is closable once it is returned, so it is closed if anything after fails.
+	if !im.withError || results[1].IsNil() {
+		c.addCloser(im, results[0].Interface())
+	}
+	if err := c.checkContext(rm, im, true); err != nil {
+		return nil, err
+	}
+	if im.withError && !results[1].IsNil() {
+		return nil, &ProviderError{
+			Module:   rm.name,
+			Method:   im.methodName,
+			Instance: rm.qualifiedName(im.name),
+			Attempts: attempt,
+			Err:      results[1].Interface().(error),
+		}
+	}
+	v := results[0]
+	if c.opts.lifecycleHooks {
+		if err := runLifecycleHooks(rm, im, v.Interface()); err != nil {
+			return nil, err
+		}
+	}
+	for _, d := range im.decorators {
+		v = d.method.Call([]reflect.Value{v})[0]
+	}
+	return v.Interface(), nil
+}
+
+// addCloser records the instance to be closed if it implements io.Closer, unless it is supplied by the caller.
+func (c *container) addCloser(im *instanceMethod, instance interface{}) {
+	if closer, ok := instance.(io.Closer); ok && !im.supplied {
+		c.closers = append(c.closers, closer)
+	}
+}
+
+// checkContext returns error if the container is created with a context which is done.
+func (c *container) checkContext(rm *reflectedModule, im *instanceMethod, running bool) error {
+	if c.ctx == nil {
+		return nil
+	}
+	return checkContext(c.ctx, rm, im, running)
+}
+
+// addAlias adds an alias of the instance with the qualified name.
+func (c *container) addAlias(alias string, name string, deprecated bool) {
+	c.aliases[alias] = &aliasTarget{
+		name:       name,
+		deprecated: deprecated,
+	}
+	if short := unqualifiedName(alias); short != "" {
+		c.qualifiedNames[short] = append(c.qualifiedNames[short], alias)
+	}
+}
+
+// addInstance adds an instance with the name and the type. A qualified name is indexed by the unqualified name as well.
+// A fallback instance is not indexed by the type.
+func (c *container) addInstance(name string, t reflect.Type, instance interface{}) {
+	c.instanceByName[name] = instance
+
+	if !c.isFallback(name) {
+		typedInstances, _ := c.instanceByType[t]
 		typedInstances = append(typedInstances, instance)
-		c.instanceByType[instanceMethod.tp] = typedInstances
+		c.instanceByType[t] = typedInstances
+	}
+
+	if short := unqualifiedName(name); short != "" {
+		c.qualifiedNames[short] = append(c.qualifiedNames[short], name)
 	}
 }
 
-func (c *container) findInstanceByType(t reflect.Type) interface{} {
+func (c *container) findInstanceByType(t reflect.Type) (interface{}, error) {
 	instances, ok := c.instanceByType[t]
 	if !ok {
 		instances = c.findAssignableInstances(t)
 	}
 	if len(instances) == 0 {
-		panic(fmt.Sprintf("instance type %s is not defined", t.Name()))
+		if instance, ok := c.findFallbackInstance(t); ok {
+			return instance, nil
+		}
+		return nil, fmt.Errorf("instance type %s is not defined", t.Name())
 	}
 	if len(instances) > 1 {
-		panic(fmt.Sprintf("instance type %s has more than one instances defined", t.Name()))
+		return nil, fmt.Errorf("instance type %s has more than one instances defined", t.Name())
+	}
+	if !ok {
+		c.opts.debug("alice: type resolved to an assignable instance", "type", t,
+			"instance", reflect.TypeOf(instances[0]))
 	}
 
-	return instances[0]
+	return instances[0], nil
 }
 
-func (c *container) findInstanceByName(name string) interface{} {
-	instance, ok := c.instanceByName[name]
-	if !ok {
-		panic(fmt.Sprintf("instance name %s is not defined", name))
+func (c *container) findInstanceByName(name string) (interface{}, error) {
+	instance, target, err := c.lookupName(name)
+	if err != nil {
+		return nil, err
 	}
-	return instance
+	if target != nil && target.deprecated {
+		c.opts.warnDeprecated("instance name", name, target.name)
+	}
+	return instance, nil
+}
+
+// lookupName finds an instance by a qualified name, or an unqualified name if it is unambiguous. The name is either an
+// instance name or an alias. It returns the target if the name is an alias.
+func (c *container) lookupName(name string) (interface{}, *aliasTarget, error) {
+	qualified := name
+	if _, ok := c.instanceByName[name]; !ok && c.aliases[name] == nil {
+		qualifiedNames := c.qualifiedNames[name]
+		if len(qualifiedNames) > 1 {
+			return nil, nil, fmt.Errorf("instance name %s is ambiguous, use one of the qualified names %v",
+				name, qualifiedNames)
+		}
+		if len(qualifiedNames) == 0 {
+			return nil, nil, fmt.Errorf("instance name %s is not defined", name)
+		}
+		qualified = qualifiedNames[0]
+		c.opts.debug("alice: unqualified name resolved", "name", name, "qualified", qualified)
+	}
+
+	target := c.aliases[qualified]
+	if target != nil {
+		qualified = target.name
+	}
+	return c.instanceByName[qualified], target, nil
 }
 
 func (c *container) findAssignableInstances(t reflect.Type) []interface{} {
 	var instances []interface{}
-	for _, instance := range c.instanceByName {
+	for name, instance := range c.instanceByName {
+		if c.isFallback(name) {
+			continue
+		}
 		instanceType := reflect.TypeOf(instance)
 		if instanceType.AssignableTo(t) {
 			instances = append(instances, instance)
@@ -116,14 +431,170 @@ func (c *container) findAssignableInstances(t reflect.Type) []interface{} {
 	return instances
 }
 
-func (c *container) reflectModules(modules []Module) []*reflectedModule {
+// fallbackInstance is the name and the type of a fallback instance.
+type fallbackInstance struct {
+	name string
+	tp   reflect.Type
+}
+
+// isFallback checks if the instance with the qualified name is a fallback.
+func (c *container) isFallback(name string) bool {
+	for _, fallback := range c.fallbacks {
+		if fallback.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// findFallbackInstance finds the first fallback instance of the type, or the first one assignable to the type if none
+// is of the exact type. Fallbacks not built yet are skipped.
+func (c *container) findFallbackInstance(t reflect.Type) (interface{}, bool) {
+	var assignable *fallbackInstance
+	for _, fallback := range c.fallbacks {
+		if _, ok := c.instanceByName[fallback.name]; !ok {
+			continue
+		}
+		if fallback.tp == t {
+			return c.instanceByName[fallback.name], true
+		}
+		if assignable == nil && fallback.tp.AssignableTo(t) {
+			assignable = fallback
+		}
+	}
+	if assignable == nil {
+		return nil, false
+	}
+	return c.instanceByName[assignable.name], true
+}
+
+// buildPlan contains everything needed to instantiate the modules, computed without calling any provider.
+type buildPlan struct {
+	graph     *graph
+	order     []*reflectedModule
+	overrides []Override
+}
+
+// plan reflects the modules, applies the options, and computes the instantiation order. It doesn't call any
+// provider method.
+func plan(modules []Module, opts *options) (*buildPlan, error) {
+	profiles := newProfileSet(opts.profiles)
+	rms, err := reflectModules(modules, profiles)
+	if err != nil {
+		return nil, err
+	}
+	overridingRms, err := reflectModules(opts.moduleOverrides, profiles)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyNamingStrategy(append(rms, overridingRms...), opts.namingStrategy); err != nil {
+		return nil, err
+	}
+	overrides := applyModuleOverrides(rms, overridingRms)
+	rms = append(rms, overridingRms...)
+
+	if len(opts.instances) > 0 {
+		rm, err := instancesModule(opts.instances)
+		if err != nil {
+			return nil, err
+		}
+		rms = append(rms, rm)
+	}
+	applyConditions(rms)
+	applyFallbacks(rms)
+
+	if err := applyNamedRetryPolicies(rms, opts.retryPolicies); err != nil {
+		return nil, err
+	}
+	instanceOverrides, err := applyInstanceOverrides(rms, opts.instanceOverrides)
+	if err != nil {
+		return nil, err
+	}
+	overrides = append(overrides, instanceOverrides...)
+
+	g, err := createGraph(rms...)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := g.instantiationOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	return &buildPlan{
+		graph:     g,
+		order:     order,
+		overrides: overrides,
+	}, nil
+}
+
+// reflectModules reflects the modules and the modules embedded in them recursively. A module is reflected only once,
+// even if it is passed or embedded multiple times. Modules and providers which are not active in the profiles are
+// skipped, then providers of embedded modules overridden by the embedding modules are removed. It returns error if any
+// of the module is invalid.
+func reflectModules(modules []Module, profiles profileSet) ([]*reflectedModule, error) {
 	var rms []*reflectedModule
-	for _, m := range c.modules {
+	reflected := make(map[Module]*reflectedModule)
+	// A module embedded by value is a separate copy, so its type must not be included again. Otherwise its names are
+	// reported as duplicated, which doesn't tell how to fix it.
+	included := make(map[reflect.Type]bool)
+	embeddedByValue := make(map[reflect.Type]string)
+	var add func(m Module, byValue string) (*reflectedModule, error)
+	add = func(m Module, byValue string) (*reflectedModule, error) {
 		rm, err := reflectModule(m)
 		if err != nil {
-			panic(err)
+			return nil, err
+		}
+		// m is a pointer after being reflected, so it is comparable.
+		if existing, ok := reflected[m]; ok {
+			return existing, nil
+		}
+		t := reflect.TypeOf(m)
+		if field, ok := embeddedByValue[t]; ok || (byValue != "" && included[t]) {
+			if !ok {
+				field = byValue
+			}
+			return nil, fmt.Errorf("module %s embedded by value in %s is included more than once; embed it by pointer "+
+				"to share a single instance", rm.name, field)
 		}
+		included[t] = true
+		if byValue != "" {
+			embeddedByValue[t] = byValue
+		}
+		if !profiles.matches(rm.profiles) {
+			reflected[m] = nil
+			return nil, nil
+		}
+		reflected[m] = rm
 		rms = append(rms, rm)
+		rm.removeInactiveProviders(profiles)
+
+		embedded, err := embeddedModules(m)
+		if err != nil {
+			return nil, err
+		}
+		for _, em := range embedded {
+			byValue := ""
+			if !em.pointer {
+				byValue = em.field
+			}
+			erm, err := add(em.module, byValue)
+			if err != nil {
+				return nil, err
+			}
+			if erm != nil {
+				rm.embedded = append(rm.embedded, erm)
+			}
+		}
+		rm.overrideEmbeddedProviders()
+		return rm, nil
+	}
+
+	for _, m := range modules {
+		if _, err := add(m, ""); err != nil {
+			return nil, err
+		}
 	}
-	return rms
+	return rms, nil
 }
@@ -1,7 +1,9 @@
 package alice
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -156,6 +158,24 @@ func (m *ModuleWithD5Impl2) D5_2() *D5Impl2 {
 	return &D5Impl2{}
 }
 
+type MismatchedNameTypeModule struct {
+	BaseModule
+	D D3 `alice:"D1"`
+}
+
+type ConcreteD1Module struct {
+	BaseModule
+	D *D1Impl `alice:"D1"`
+}
+
+type FakeD1Module struct {
+	BaseModule
+}
+
+func (m *FakeD1Module) D1() D1 {
+	return &D1Fake{}
+}
+
 type SelfDependModule struct {
 	BaseModule
 	D D1 `alice:"D1"`
@@ -165,6 +185,44 @@ func (m *SelfDependModule) D1() D1 {
 	return &D1Impl{}
 }
 
+type ComposedModule struct {
+	BaseModule
+	M1
+	*M4
+}
+
+type SiblingModule struct {
+	BaseModule
+	M1
+}
+
+type VendorModule struct {
+	BaseModule
+}
+
+func (m *VendorModule) D1() D1 {
+	return &D1Impl{}
+}
+
+func (m *VendorModule) D2() D2 {
+	return &D2Impl{}
+}
+
+type decoratedD1 struct {
+	base D1
+}
+
+func (d *decoratedD1) D1() {}
+
+type CustomizedModule struct {
+	BaseModule
+	VendorModule
+}
+
+func (m *CustomizedModule) D1() D1 {
+	return &decoratedD1{m.VendorModule.D1()}
+}
+
 //***********************************************************
 
 func TestPopulate(t *testing.T) {
@@ -382,3 +440,152 @@ func TestCreateContainer(t *testing.T) {
 		t.Errorf("bad instance after CreateContainer(): got %v, expected %v", d1, expectedD1)
 	}
 }
+
+func TestNewContainer(t *testing.T) {
+	c, err := NewContainer(&M1{}, &M2{}, &M3{}, &M4{}, &M5{})
+	if err != nil {
+		t.Errorf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	d1 := c.InstanceByName("D1").(D1)
+	expectedD1 := &D1Impl{}
+	if !reflect.DeepEqual(d1, expectedD1) {
+		t.Errorf("bad instance after NewContainer(): got %v, expected %v", d1, expectedD1)
+	}
+}
+
+func TestNewContainer_Error(t *testing.T) {
+	_, err := NewContainer(&M1{}, &M1Duplicated{})
+	if err == nil {
+		t.Error("expect error after NewContainer() on invalid modules")
+	}
+	t.Log(err.Error())
+}
+
+func TestNewContainer_EmbeddedModules(t *testing.T) {
+	m4 := &M4{}
+	composed := &ComposedModule{M4: m4}
+
+	// m4 is both embedded and passed, so it is only included once.
+	c, err := NewContainer(composed, m4, &M2{}, &M3{})
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	d3 := c.InstanceByName("D3").(D3)
+	expectedD3 := &D3Impl{}
+	if !reflect.DeepEqual(d3, expectedD3) {
+		t.Errorf("bad instance after NewContainer(): got %v, expected %v", d3, expectedD3)
+	}
+	expectedD1 := &D1Impl{}
+	if !reflect.DeepEqual(m4.D1, expectedD1) {
+		t.Errorf("bad field of embedded module after NewContainer(): got %v, expected %v", m4.D1, expectedD1)
+	}
+}
+
+func TestNewContainer_NilEmbeddedModule(t *testing.T) {
+	_, err := NewContainer(&ComposedModule{}, &M2{}, &M3{})
+	if err == nil {
+		t.Error("expect error after NewContainer() on nil embedded module")
+	}
+	t.Log(err.Error())
+}
+
+func TestNewContainer_DuplicatedValueEmbeddedModule(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []Module
+	}{
+		{"embedded in two modules", []Module{&ComposedModule{M4: &M4{}}, &SiblingModule{}, &M2{}, &M3{}}},
+		{"embedded and passed", []Module{&ComposedModule{M4: &M4{}}, &M1{}, &M2{}, &M3{}}},
+		{"passed and embedded", []Module{&M1{}, &ComposedModule{M4: &M4{}}, &M2{}, &M3{}}},
+	}
+	for _, test := range tests {
+		_, err := NewContainer(test.items...)
+		if err == nil || !strings.Contains(err.Error(), "module M1 embedded by value in ComposedModule.M1") {
+			t.Errorf("bad error after NewContainer() on module %s: got %v", test.name, err)
+		}
+	}
+}
+
+func TestNewContainer_OverrideEmbeddedProvider(t *testing.T) {
+	c, err := NewContainer(&CustomizedModule{}, &M2{}, &M3{}, &M4{})
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	d1 := c.InstanceByName("D1").(D1)
+	expectedD1 := &decoratedD1{&D1Impl{}}
+	if !reflect.DeepEqual(d1, expectedD1) {
+		t.Errorf("bad overriding instance after NewContainer(): got %v, expected %v", d1, expectedD1)
+	}
+	d2 := c.InstanceByName("D2").(D2)
+	expectedD2 := &D2Impl{}
+	if !reflect.DeepEqual(d2, expectedD2) {
+		t.Errorf("bad embedded instance after NewContainer(): got %v, expected %v", d2, expectedD2)
+	}
+}
+
+type FailingCloseModule struct {
+	BaseModule
+	Closed []string
+}
+
+func (m *FailingCloseModule) Pool() *closingInstance {
+	return &closingInstance{name: "Pool", closed: &m.Closed, err: errors.New("pool is busy")}
+}
+
+func TestContainer_Close(t *testing.T) {
+	closing := &ClosingModule{}
+	failing := &FailingCloseModule{}
+	supplied := &closingInstance{name: "Supplied", closed: &closing.Closed}
+	var closedEvents int
+	c, err := NewContainerWithOptions([]Module{closing, failing}, WithInstance("Supplied", supplied),
+		WithListener(func(e Event) {
+			if e.Kind == ContainerClosed {
+				closedEvents++
+			}
+		}))
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+	if closing.Closed != nil || failing.Closed != nil {
+		t.Fatalf("bad closed instances before Close(): got %v, %v", closing.Closed, failing.Closed)
+	}
+
+	err = c.Close()
+	if err == nil || err.Error() != "pool is busy" {
+		t.Errorf("bad error after Close(): got %v, expected pool is busy", err)
+	}
+	if expected := []string{"Second", "First"}; !reflect.DeepEqual(closing.Closed, expected) {
+		t.Errorf("bad closed instances after Close(): got %v, expected %v", closing.Closed, expected)
+	}
+	if expected := []string{"Pool"}; !reflect.DeepEqual(failing.Closed, expected) {
+		t.Errorf("bad closed instances after Close(): got %v, expected %v", failing.Closed, expected)
+	}
+	if closedEvents != 1 {
+		t.Errorf("bad ContainerClosed events after Close(): got %d, expected 1", closedEvents)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Errorf("unexpected error after closing twice: %s", err.Error())
+	}
+	if len(closing.Closed) != 2 || len(failing.Closed) != 1 {
+		t.Errorf("bad closed instances after closing twice: got %v, %v", closing.Closed, failing.Closed)
+	}
+}
+
+func TestNewContainer_InterfaceToConcreteField(t *testing.T) {
+	concrete := &ConcreteD1Module{}
+	if _, err := NewContainer(&M1{}, concrete); err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+	if !reflect.DeepEqual(concrete.D, &D1Impl{}) {
+		t.Errorf("bad concrete field after NewContainer(): got %v, expected %v", concrete.D, &D1Impl{})
+	}
+
+	_, err := NewContainer(&FakeD1Module{}, &ConcreteD1Module{})
+	if err == nil || !strings.Contains(err.Error(), "is not assignable from instance D1 of type *alice.D1Fake") {
+		t.Errorf("bad error after NewContainer() on instance of another type: got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package alice
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+var _ContextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// NewContainerContext creates a new instance of container with specified modules, the same as NewContainer, except
+// that providers taking a context.Context as their only parameter are called with ctx. It is the same as
+// NewContainerWithOptions with WithContext(ctx).
+func NewContainerContext(ctx context.Context, modules ...Module) (Container, error) {
+	return NewContainerWithOptions(modules, WithContext(ctx))
+}
+
+// WithContext passes ctx to the providers taking a context.Context as their only parameter:
+//
+//	func (m *PersistModule) DB(ctx context.Context) *sql.DB {
+//		db, _ := sql.Open("mysql", m.DSN)
+//		if err := db.PingContext(ctx); err != nil {
+//			...
+//		}
+//		return db
+//	}
+//
+// No more providers are called once ctx is done, and the returned error names the provider which was running. The
+// error wraps ctx.Err(), so it could be checked by errors.Is(err, context.DeadlineExceeded). Providers without a
+// context parameter are not interrupted, so a deadline is only enforced between calls.
+func WithContext(ctx context.Context) Option {
+	return optionFunc(func(o *options) {
+		o.ctx = ctx
+	})
+}
+
+// takesContext checks if the provider method, without the receiver, takes a context.Context as its only parameter.
+func takesContext(t reflect.Type) bool {
+	return t.NumIn() == 2 && t.In(1) == _ContextType
+}
+
+// checkContext returns error naming the provider if ctx is done. running tells whether the provider was running or
+// about to be called when ctx was found done.
+func checkContext(ctx context.Context, rm *reflectedModule, im *instanceMethod, running bool) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	state := "before calling"
+	if running {
+		state = "while running"
+	}
+	return fmt.Errorf("container build is stopped %s provider %s.%s of instance %s: %w",
+		state, rm.name, im.methodName, rm.qualifiedName(im.name), ctx.Err())
+}
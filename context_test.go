@@ -0,0 +1,126 @@
+package alice
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type ctxKey struct{}
+
+type ContextModule struct {
+	BaseModule
+	cancel func()
+	called []string
+}
+
+func (m *ContextModule) Conn(ctx context.Context) string {
+	m.called = append(m.called, "Conn")
+	if m.cancel != nil {
+		m.cancel()
+	}
+	value, _ := ctx.Value(ctxKey{}).(string)
+	return value
+}
+
+func (m *ContextModule) Pool() D1 {
+	m.called = append(m.called, "Pool")
+	return &D1Impl{}
+}
+
+type ClosingContextModule struct {
+	BaseModule
+	cancel func()
+	Closed []string
+}
+
+func (m *ClosingContextModule) Conn(ctx context.Context) *closingInstance {
+	m.cancel()
+	return &closingInstance{name: "Conn", closed: &m.Closed}
+}
+
+func TestNewContainerContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKey{}, "dialed")
+	c, err := NewContainerContext(ctx, &ContextModule{})
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainerContext(): %s", err.Error())
+	}
+
+	if conn := c.InstanceByName("Conn"); conn != "dialed" {
+		t.Errorf("bad instance Conn: got %v, expected dialed", conn)
+	}
+}
+
+func TestNewContainerContext_Background(t *testing.T) {
+	c, err := NewContainer(&ContextModule{})
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if conn := c.InstanceByName("Conn"); conn != "" {
+		t.Errorf("bad instance Conn: got %v, expected empty", conn)
+	}
+}
+
+func TestNewContainerContext_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &ContextModule{cancel: cancel}
+	_, err := NewContainerContext(ctx, m)
+	if err == nil {
+		t.Fatal("expect error after NewContainerContext() on canceled context")
+	}
+	t.Log(err.Error())
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("bad error: got %v, expected to wrap %v", err, context.Canceled)
+	}
+	if !strings.Contains(err.Error(), "while running provider ContextModule.Conn") {
+		t.Errorf("bad error: got %s, expected to name the running provider", err.Error())
+	}
+	if len(m.called) != 1 {
+		t.Errorf("bad providers called: got %v, expected [Conn]", m.called)
+	}
+}
+
+func TestNewContainerContext_CanceledClosesInstance(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &ClosingContextModule{cancel: cancel}
+	_, err := NewContainerContext(ctx, m)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("bad error after NewContainerContext(): got %v, expected to wrap %v", err, context.Canceled)
+	}
+
+	if len(m.Closed) != 1 || m.Closed[0] != "Conn" {
+		t.Errorf("bad closed instances: got %v, expected [Conn] returned before the context is checked", m.Closed)
+	}
+}
+
+func TestNewContainerContext_Done(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m := &ContextModule{}
+	_, err := NewContainerContext(ctx, m)
+	if err == nil {
+		t.Fatal("expect error after NewContainerContext() on done context")
+	}
+
+	if !strings.Contains(err.Error(), "before calling provider ContextModule.Conn") {
+		t.Errorf("bad error: got %s, expected to name the next provider", err.Error())
+	}
+	if len(m.called) != 0 {
+		t.Errorf("bad providers called: got %v, expected none", m.called)
+	}
+}
+
+func TestReflectModule_ContextParameter(t *testing.T) {
+	rm, err := reflectModule(&ContextModule{})
+	if err != nil {
+		t.Fatalf("unexpected error after reflectModule(): %s", err.Error())
+	}
+
+	if !rm.instances[0].withContext || rm.instances[1].withContext {
+		t.Errorf("bad withContext: got %v and %v, expected true and false",
+			rm.instances[0].withContext, rm.instances[1].withContext)
+	}
+}
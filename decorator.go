@@ -0,0 +1,107 @@
+package alice
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+const _AliceDecoratorsMethodName = "AliceDecorators"
+
+// DecoratingModule is implemented by modules which decorate instances provided by other modules, e.g. wrapping an
+// HTTP client with retries or a DAO with caching. A decorator is a method which takes the instance and returns a
+// replacement of the same type. It could use the fields of its module, which are injected as usual.
+//
+//	func (m *RetryModule) AliceDecorators() map[string]string {
+//		return map[string]string{
+//			"WithRetries": "HTTPClient",
+//		}
+//	}
+//
+//	func (m *RetryModule) WithRetries(client HTTPClient) HTTPClient {
+//		return &retryingClient{client: client, retries: m.Retries}
+//	}
+//
+// Decorators run before the instance is published, so dependents only see the decorated instance. A decorating module
+// is instantiated before the modules whose instances it decorates. Multiple decorators of an instance are applied in
+// the order of modules, and by method name within a module, each wrapping the result of the previous one.
+type DecoratingModule interface {
+	Module
+	// AliceDecorators returns the names of the decorated instances, keyed by the decorator method names.
+	AliceDecorators() map[string]string
+}
+
+// decoratorMethod is a method decorating the instance with the target name.
+type decoratorMethod struct {
+	name   string
+	target string
+	tp     reflect.Type
+	method reflect.Value
+}
+
+// moduleDecorators returns the decorators of the module sorted by method name, if it implements DecoratingModule. It
+// returns error if a decorator is not defined, or doesn't take and return the same type.
+func moduleDecorators(m Module) ([]*decoratorMethod, error) {
+	dm, ok := m.(DecoratingModule)
+	if !ok || !declaresHook(m, _AliceDecoratorsMethodName) {
+		return nil, nil
+	}
+
+	v := reflect.ValueOf(m)
+	name := v.Elem().Type().Name()
+	var decorators []*decoratorMethod
+	for method, target := range dm.AliceDecorators() {
+		mv := v.MethodByName(method)
+		if !mv.IsValid() {
+			return nil, fmt.Errorf("decorator %s.%s is not defined", name, method)
+		}
+		t := mv.Type()
+		if t.NumIn() != 1 || t.NumOut() != 1 || t.In(0) != t.Out(0) {
+			return nil, fmt.Errorf("decorator %s.%s doesn't take and return the same type", name, method)
+		}
+		decorators = append(decorators, &decoratorMethod{
+			name:   method,
+			target: target,
+			tp:     t.Out(0),
+			method: mv,
+		})
+	}
+	sort.Slice(decorators, func(i, j int) bool {
+		return decorators[i].name < decorators[j].name
+	})
+	return decorators, nil
+}
+
+// isDecorator checks if the method is one of the decorators.
+func isDecorator(decorators []*decoratorMethod, method string) bool {
+	for _, d := range decorators {
+		if d.name == method {
+			return true
+		}
+	}
+	return false
+}
+
+// createDecorations attaches the decorators of a module to the instances they decorate. The providers of the
+// instances depend on the module, so the decorators could use its injected fields.
+func (g *graph) createDecorations(rm *reflectedModule, names *nameIndex) error {
+	for _, d := range rm.decorators {
+		provider, instance, candidates := names.lookup(d.target)
+		if len(candidates) > 1 {
+			return fmt.Errorf("decorated name %s of %s.%s is ambiguous, use one of the qualified names %v",
+				d.target, rm.name, d.name, candidates)
+		}
+		if provider == nil {
+			return fmt.Errorf("decorated name %s of %s.%s is not found", d.target, rm.name, d.name)
+		}
+		if instance.tp != d.tp {
+			return fmt.Errorf("decorator %s.%s of type %s doesn't match %s.%s of type %s",
+				rm.name, d.name, d.tp, provider.name, instance.name, instance.tp)
+		}
+		instance.decorators = append(instance.decorators, d)
+		if provider != rm {
+			g.addDependencyEdge(rm, provider)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package alice
+
+import (
+	"reflect"
+	"testing"
+)
+
+type retryingD1 struct {
+	base    D1
+	retries int
+}
+
+func (d *retryingD1) D1() {}
+
+type RetryModule struct {
+	BaseModule
+	Retries int `alice:"Retries"`
+}
+
+func (m *RetryModule) AliceDecorators() map[string]string {
+	return map[string]string{
+		"WithRetries": "D1",
+	}
+}
+
+func (m *RetryModule) WithRetries(d1 D1) D1 {
+	return &retryingD1{base: d1, retries: m.Retries}
+}
+
+type MetricsModule struct {
+	BaseModule
+}
+
+func (m *MetricsModule) AliceDecorators() map[string]string {
+	return map[string]string{
+		"WithMetrics": "D1",
+		"Decorate":    "D1",
+	}
+}
+
+func (m *MetricsModule) WithMetrics(d1 D1) D1 {
+	return &decoratedD1{base: d1}
+}
+
+func (m *MetricsModule) Decorate(d1 D1) D1 {
+	return &retryingD1{base: d1}
+}
+
+type MetricsServerModule struct {
+	BaseModule
+	*MetricsModule
+}
+
+func (m *MetricsServerModule) Address() string {
+	return ":8080"
+}
+
+type mismatchedDecoratorModule struct {
+	BaseModule
+}
+
+func (m *mismatchedDecoratorModule) AliceDecorators() map[string]string {
+	return map[string]string{
+		"Decorate": "D1",
+	}
+}
+
+func (m *mismatchedDecoratorModule) Decorate(d1 D1) D2 {
+	return nil
+}
+
+type MisdirectedDecoratorModule struct {
+	BaseModule
+}
+
+func (m *MisdirectedDecoratorModule) AliceDecorators() map[string]string {
+	return map[string]string{
+		"Decorate": "D2",
+	}
+}
+
+func (m *MisdirectedDecoratorModule) Decorate(d1 D1) D1 {
+	return d1
+}
+
+func TestDecorators(t *testing.T) {
+	m2 := &M2{}
+	c, err := NewContainerWithOptions([]Module{&MetricsModule{}, &RetryModule{}, &M1{}, m2, &M4{}},
+		WithInstance("Retries", 3))
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	// MetricsModule.Decorate, MetricsModule.WithMetrics, then RetryModule.WithRetries.
+	expected := &retryingD1{
+		base:    &decoratedD1{base: &retryingD1{base: &D1Impl{}}},
+		retries: 3,
+	}
+	if !reflect.DeepEqual(m2.D1, expected) {
+		t.Errorf("bad decorated D1: got %v, expected %v", m2.D1, expected)
+	}
+	if d1 := c.InstanceByName("D1"); d1 != m2.D1 {
+		t.Errorf("bad instance after InstanceByName() of decorated D1: got %v, expected %v", d1, m2.D1)
+	}
+	if d1 := c.Instance(reflect.TypeOf((*D1)(nil)).Elem()); d1 != m2.D1 {
+		t.Errorf("bad instance after Instance() of decorated D1: got %v, expected %v", d1, m2.D1)
+	}
+}
+
+func TestDecorators_EmbeddedModule(t *testing.T) {
+	m2 := &M2{}
+	_, err := NewContainer(&MetricsServerModule{MetricsModule: &MetricsModule{}}, &M1{}, m2, &M4{})
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	// The decorators of MetricsModule apply once, rather than again for MetricsServerModule.
+	expected := &decoratedD1{base: &retryingD1{base: &D1Impl{}}}
+	if !reflect.DeepEqual(m2.D1, expected) {
+		t.Errorf("bad decorated D1: got %v, expected %v", m2.D1, expected)
+	}
+}
+
+func TestDecorators_Mismatched(t *testing.T) {
+	_, err := reflectModule(&mismatchedDecoratorModule{})
+	if err == nil {
+		t.Error("expect error after reflectModule() on decorator returning another type")
+	}
+	t.Log(err.Error())
+
+	_, err = NewContainer(&M1{}, &MisdirectedDecoratorModule{})
+	if err == nil {
+		t.Error("expect error after NewContainer() on decorator of another type")
+	}
+	t.Log(err.Error())
+}
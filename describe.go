@@ -0,0 +1,197 @@
+package alice
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+)
+
+// GraphSchemaVersion is the version of the JSON schema of GraphDescription. It is increased on every incompatible
+// change of the schema. Adding new properties is not considered incompatible.
+const GraphSchemaVersion = 1
+
+// Kinds of fields, describing how a field is associated with an instance.
+const (
+	// FieldKindName is the kind of fields tagged by `alice:"Name"`, which are associated by instance name.
+	FieldKindName = "name"
+	// FieldKindType is the kind of fields tagged by `alice:""`, which are associated by instance type.
+	FieldKindType = "type"
+)
+
+// GraphDescription describes the dependency graph of modules. It is serializable to stable JSON, so that tools
+// could consume the graph without importing alice. The JSON schema (version 1) is:
+//
+//	{
+//	  "version": 1,
+//	  "modules": [
+//	    {
+//	      "id": "github.com/magic003/alice/example/module.ClientModule",
+//	      "name": "ClientModule",
+//	      "package": "github.com/magic003/alice/example/module",
+//	      "instances": [{"name": "HTTPClient", "type": "client.HTTPClient"}],
+//	      "fields": [{"name": "Retries", "type": "int", "kind": "name", "dependency": "Retries"}]
+//	    }
+//	  ],
+//	  "edges": [
+//	    {
+//	      "from": "Retries",
+//	      "to": "github.com/magic003/alice/example/module.ClientModule",
+//	      "field": "Retries",
+//	      "kind": "name"
+//	    }
+//	  ],
+//	  "order": ["github.com/magic003/alice/example/module.ConfigModule", "..."]
+//	}
+//
+// Modules are identified by id, which is the package path and the module name. Instances are identified by name,
+// which is unique in a graph. The name of an instance in a namespace is qualified, e.g. "payments.Client". An edge goes
+// from an instance to the module whose field it is assigned to. Order lists the module ids in instantiation order.
+type GraphDescription struct {
+	Version int                  `json:"version"`
+	Modules []*ModuleDescription `json:"modules"`
+	Edges   []*EdgeDescription   `json:"edges"`
+	Order   []string             `json:"order"`
+}
+
+// ModuleDescription describes a module in the dependency graph. Namespace is empty if the module doesn't declare one.
+type ModuleDescription struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Package   string                 `json:"package"`
+	Namespace string                 `json:"namespace,omitempty"`
+	Instances []*InstanceDescription `json:"instances"`
+	Fields    []*FieldDescription    `json:"fields"`
+}
+
+// InstanceDescription describes an instance provided by a module.
+type InstanceDescription struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// FieldDescription describes a tagged field of a module.
+type FieldDescription struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Kind is either FieldKindName or FieldKindType.
+	Kind string `json:"kind"`
+	// Dependency is the instance name in the tag. It is empty for fields associated by type.
+	Dependency string `json:"dependency,omitempty"`
+}
+
+// EdgeDescription describes a dependency from a field to the instance assigned to it.
+type EdgeDescription struct {
+	// From is the qualified name of the instance.
+	From string `json:"from"`
+	// To is the id of the module whose field the instance is assigned to.
+	To string `json:"to"`
+	// Field is the name of the field.
+	Field string `json:"field"`
+	// Kind is either FieldKindName or FieldKindType.
+	Kind string `json:"kind"`
+}
+
+// DescribeGraph describes the dependency graph of the modules. No provider method is called. It returns error if the
+// modules are invalid, the same as Validate.
+func DescribeGraph(modules ...Module) (*GraphDescription, error) {
+	p, err := plan(modules, defaultOptions())
+	if err != nil {
+		return nil, err
+	}
+	return describeGraph(p), nil
+}
+
+// ExportJSON writes the dependency graph of the modules as JSON. See GraphDescription for the schema.
+func ExportJSON(w io.Writer, modules ...Module) error {
+	d, err := DescribeGraph(modules...)
+	if err != nil {
+		return err
+	}
+	return d.WriteJSON(w)
+}
+
+// WriteJSON writes the graph description as indented JSON.
+func (d *GraphDescription) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(d)
+}
+
+// describeGraph creates the description of the graph in a build plan.
+func describeGraph(p *buildPlan) *GraphDescription {
+	d := &GraphDescription{
+		Version: GraphSchemaVersion,
+		Modules: []*ModuleDescription{},
+		Edges:   []*EdgeDescription{},
+		Order:   []string{},
+	}
+	for _, rm := range p.graph.modules {
+		d.Modules = append(d.Modules, describeModule(rm))
+	}
+	for _, edge := range p.graph.edges {
+		kind := FieldKindType
+		if edge.named {
+			kind = FieldKindName
+		}
+		d.Edges = append(d.Edges, &EdgeDescription{
+			From:  edge.provider.qualifiedName(edge.instance.name),
+			To:    moduleID(edge.dependant),
+			Field: edge.fieldName,
+			Kind:  kind,
+		})
+	}
+	for _, rm := range p.order {
+		d.Order = append(d.Order, moduleID(rm))
+	}
+	return d
+}
+
+// describeModule creates the description of a module.
+func describeModule(rm *reflectedModule) *ModuleDescription {
+	md := &ModuleDescription{
+		ID:        moduleID(rm),
+		Name:      rm.name,
+		Package:   modulePackage(rm),
+		Namespace: rm.namespace,
+		Instances: []*InstanceDescription{},
+		Fields:    []*FieldDescription{},
+	}
+	for _, instance := range rm.instances {
+		md.Instances = append(md.Instances, &InstanceDescription{
+			Name: rm.qualifiedName(instance.name),
+			Type: instance.tp.String(),
+		})
+	}
+	for _, dep := range rm.namedDepends {
+		md.Fields = append(md.Fields, &FieldDescription{
+			Name:       dep.fieldName,
+			Type:       dep.field.Type().String(),
+			Kind:       FieldKindName,
+			Dependency: dep.name,
+		})
+	}
+	for _, dep := range rm.typedDepends {
+		md.Fields = append(md.Fields, &FieldDescription{
+			Name: dep.fieldName,
+			Type: dep.tp.String(),
+			Kind: FieldKindType,
+		})
+	}
+	return md
+}
+
+// moduleID returns the id of a module, which is the package path and the module name.
+func moduleID(rm *reflectedModule) string {
+	if pkg := modulePackage(rm); pkg != "" {
+		return pkg + "." + rm.name
+	}
+	return rm.name
+}
+
+// modulePackage returns the package path of a module, or empty if it is not defined in a package.
+func modulePackage(rm *reflectedModule) string {
+	if rm.m == nil {
+		return ""
+	}
+	return reflect.TypeOf(rm.m).Elem().PkgPath()
+}
@@ -0,0 +1,112 @@
+package alice
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDescribeGraph(t *testing.T) {
+	d, err := DescribeGraph(&M1{}, &M4{})
+	if err != nil {
+		t.Errorf("unexpected error after DescribeGraph(): %s", err.Error())
+	}
+
+	expected := &GraphDescription{
+		Version: GraphSchemaVersion,
+		Modules: []*ModuleDescription{
+			{
+				ID:      "github.com/magic003/alice.M1",
+				Name:    "M1",
+				Package: "github.com/magic003/alice",
+				Instances: []*InstanceDescription{
+					{Name: "D1", Type: "alice.D1"},
+					{Name: "D2", Type: "alice.D2"},
+				},
+				Fields: []*FieldDescription{},
+			},
+			{
+				ID:      "github.com/magic003/alice.M4",
+				Name:    "M4",
+				Package: "github.com/magic003/alice",
+				Instances: []*InstanceDescription{
+					{Name: "D3", Type: "alice.D3"},
+					{Name: "D4", Type: "alice.D4"},
+				},
+				Fields: []*FieldDescription{
+					{Name: "D1", Type: "alice.D1", Kind: FieldKindName, Dependency: "D1"},
+				},
+			},
+		},
+		Edges: []*EdgeDescription{
+			{From: "D1", To: "github.com/magic003/alice.M4", Field: "D1", Kind: FieldKindName},
+		},
+		Order: []string{"github.com/magic003/alice.M1", "github.com/magic003/alice.M4"},
+	}
+	if !reflect.DeepEqual(d, expected) {
+		t.Errorf("bad description after DescribeGraph(): got %+v, expected %+v", d, expected)
+	}
+}
+
+type OrderAModule struct {
+	BaseModule
+	D1 D1 `alice:"D1"`
+}
+
+type OrderBModule struct {
+	BaseModule
+	D1 D1 `alice:"D1"`
+}
+
+func TestDescribeGraph_Order(t *testing.T) {
+	expected := []string{
+		"github.com/magic003/alice.M1",
+		"github.com/magic003/alice.OrderBModule",
+		"github.com/magic003/alice.OrderAModule",
+		"github.com/magic003/alice.M4",
+	}
+	// the dependants of M1 used to be visited in the order of a map.
+	for i := 0; i < 50; i++ {
+		d, err := DescribeGraph(&M1{}, &M4{}, &OrderAModule{}, &OrderBModule{})
+		if err != nil {
+			t.Fatalf("unexpected error after DescribeGraph(): %s", err.Error())
+		}
+		if !reflect.DeepEqual(d.Order, expected) {
+			t.Fatalf("bad order after DescribeGraph(): got %v, expected %v", d.Order, expected)
+		}
+	}
+}
+
+func TestDescribeGraph_Error(t *testing.T) {
+	_, err := DescribeGraph(&M4{})
+	if err == nil {
+		t.Error("expect error after DescribeGraph() on invalid modules")
+	}
+	t.Log(err.Error())
+}
+
+func TestExportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportJSON(&buf, &M1{}, &M3{}, &ModuleWithD5Impl1{}); err != nil {
+		t.Errorf("unexpected error after ExportJSON(): %s", err.Error())
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Errorf("unexpected error decoding JSON from ExportJSON(): %s", err.Error())
+	}
+	if decoded["version"] != float64(GraphSchemaVersion) {
+		t.Errorf("bad version in JSON: got %v, expected %v", decoded["version"], GraphSchemaVersion)
+	}
+	edges := decoded["edges"].([]interface{})
+	expectedEdge := map[string]interface{}{
+		"from":  "D5_1",
+		"to":    "github.com/magic003/alice.M3",
+		"field": "D5",
+		"kind":  "type",
+	}
+	if len(edges) != 1 || !reflect.DeepEqual(edges[0], expectedEdge) {
+		t.Errorf("bad edges in JSON: got %v, expected %v", edges, []interface{}{expectedEdge})
+	}
+}
@@ -0,0 +1,64 @@
+package alice
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExportDOT renders the dependency graph of the modules in Graphviz DOT format. Modules are rendered as clusters and
+// instances as nodes in them. Each edge goes from an instance to the module whose field it is assigned to, and is
+// labeled with the field name. Edges of fields associated by name are solid, and those associated by type are
+// dashed. No provider method is called. It returns error if the modules are invalid, the same as Validate.
+func ExportDOT(w io.Writer, modules ...Module) error {
+	d, err := DescribeGraph(modules...)
+	if err != nil {
+		return err
+	}
+	return d.WriteDOT(w)
+}
+
+// WriteDOT writes the graph description in Graphviz DOT format. See ExportDOT for how the graph is rendered.
+func (d *GraphDescription) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	clusterIDs := make(map[string]string)
+
+	fmt.Fprintln(bw, "digraph alice {")
+	fmt.Fprintln(bw, "\tcompound=true;")
+	fmt.Fprintln(bw, "\trankdir=LR;")
+	fmt.Fprintln(bw, "\tnode [shape=box];")
+	for i, md := range d.Modules {
+		id := fmt.Sprintf("cluster_%d", i)
+		clusterIDs[md.ID] = id
+		fmt.Fprintf(bw, "\tsubgraph %s {\n", id)
+		fmt.Fprintf(bw, "\t\tlabel=%s;\n", dotQuote(md.Name))
+		// anchor is the target of edges to the module, which could have no instance.
+		fmt.Fprintf(bw, "\t\t%s [shape=point, style=invis];\n", dotQuote(id))
+		for _, instance := range md.Instances {
+			fmt.Fprintf(bw, "\t\t%s [label=%s];\n",
+				dotQuote(instance.Name), dotQuote(instance.Name+"\n"+instance.Type))
+		}
+		fmt.Fprintln(bw, "\t}")
+	}
+	for _, edge := range d.Edges {
+		style := "dashed"
+		if edge.Kind == FieldKindName {
+			style = "solid"
+		}
+		id := clusterIDs[edge.To]
+		fmt.Fprintf(bw, "\t%s -> %s [label=%s, lhead=%s, style=%s];\n",
+			dotQuote(edge.From), dotQuote(id), dotQuote(edge.Field), id, style)
+	}
+	fmt.Fprintln(bw, "}")
+
+	return bw.Flush()
+}
+
+// dotQuote returns a quoted DOT string.
+func dotQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return `"` + s + `"`
+}
@@ -0,0 +1,64 @@
+package alice
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExportDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportDOT(&buf, &M1{}, &M3{}, &M4{}, &ModuleWithD5Impl1{}); err != nil {
+		t.Errorf("unexpected error after ExportDOT(): %s", err.Error())
+	}
+
+	expected := `digraph alice {
+	compound=true;
+	rankdir=LR;
+	node [shape=box];
+	subgraph cluster_0 {
+		label="M1";
+		"cluster_0" [shape=point, style=invis];
+		"D1" [label="D1\nalice.D1"];
+		"D2" [label="D2\nalice.D2"];
+	}
+	subgraph cluster_1 {
+		label="M3";
+		"cluster_1" [shape=point, style=invis];
+		"DM3" [label="DM3\nalice.D1"];
+	}
+	subgraph cluster_2 {
+		label="M4";
+		"cluster_2" [shape=point, style=invis];
+		"D3" [label="D3\nalice.D3"];
+		"D4" [label="D4\nalice.D4"];
+	}
+	subgraph cluster_3 {
+		label="ModuleWithD5Impl1";
+		"cluster_3" [shape=point, style=invis];
+		"D5_1" [label="D5_1\n*alice.D5Impl"];
+	}
+	"D5_1" -> "cluster_1" [label="D5", lhead=cluster_1, style=dashed];
+	"D1" -> "cluster_2" [label="D1", lhead=cluster_2, style=solid];
+}
+`
+	if buf.String() != expected {
+		t.Errorf("bad DOT after ExportDOT(): got\n%s\nexpected\n%s", buf.String(), expected)
+	}
+}
+
+func TestExportDOT_Error(t *testing.T) {
+	var buf bytes.Buffer
+	err := ExportDOT(&buf, &M4{})
+	if err == nil {
+		t.Error("expect error after ExportDOT() on invalid modules")
+	}
+	t.Log(err.Error())
+}
+
+func TestDOTQuote(t *testing.T) {
+	quoted := dotQuote("a\"b\\c\nd")
+	expected := `"a\"b\\c\nd"`
+	if quoted != expected {
+		t.Errorf("bad quoted string after dotQuote(): got %s, expected %s", quoted, expected)
+	}
+}
@@ -0,0 +1,96 @@
+package alice
+
+import (
+	"reflect"
+	"time"
+)
+
+// EventKind is the kind of an Event.
+type EventKind int
+
+const (
+	// ModuleReflected is emitted for each module to instantiate, in the instantiation order, before any of them is
+	// instantiated.
+	ModuleReflected EventKind = iota
+	// GraphBuilt is emitted once the instantiation order is computed.
+	GraphBuilt
+	// ProviderCalling is emitted before a provider method is called, once for each attempt if it is retried.
+	ProviderCalling
+	// ProviderCalled is emitted after a provider method returns, and its lifecycle hooks and decorators run, once for
+	// each attempt if it is retried.
+	ProviderCalled
+	// InstancePublished is emitted when an instance is added to the container, so dependents could retrieve it.
+	InstancePublished
+	// ContainerClosed is emitted when the container is closed.
+	ContainerClosed
+)
+
+// String returns the name of the kind, e.g. ProviderCalled.
+func (k EventKind) String() string {
+	switch k {
+	case ModuleReflected:
+		return "ModuleReflected"
+	case GraphBuilt:
+		return "GraphBuilt"
+	case ProviderCalling:
+		return "ProviderCalling"
+	case ProviderCalled:
+		return "ProviderCalled"
+	case InstancePublished:
+		return "InstancePublished"
+	case ContainerClosed:
+		return "ContainerClosed"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event is emitted to listeners while a container is built and closed. Fields which don't apply to the kind are left
+// empty.
+type Event struct {
+	Kind EventKind
+	// Module is the name of the module. It is set for all kinds except GraphBuilt and ContainerClosed.
+	Module string
+	// Method is the name of the provider method, set for ProviderCalling and ProviderCalled.
+	Method string
+	// Instance is the qualified name of the instance, set for ProviderCalling, ProviderCalled and InstancePublished.
+	Instance string
+	// Type is the type of the instance, set for InstancePublished.
+	Type reflect.Type
+	// Order is the names of the modules in the instantiation order, set for GraphBuilt.
+	Order []string
+	// Attempt is the number of the call of the provider, starting from 1, set for ProviderCalling and ProviderCalled.
+	Attempt int
+	// Duration is the time taken by the provider, including its lifecycle hooks and decorators, set for
+	// ProviderCalled.
+	Duration time.Duration
+	// Err is the error failing the provider, set for ProviderCalled if any.
+	Err error
+}
+
+// Listener receives the events of a container. It is called synchronously on the goroutine building the container,
+// so it should return quickly.
+type Listener func(e Event)
+
+// WithListener registers a listener of the events emitted while the container is built and closed. It is the hook to
+// attach logging, metrics or tracing without alice depending on any of them:
+//
+//	alice.WithListener(func(e alice.Event) {
+//		if e.Kind == alice.ProviderCalled {
+//			providerDuration.WithLabelValues(e.Instance).Observe(e.Duration.Seconds())
+//		}
+//	})
+//
+// Listeners are called in the order they are registered.
+func WithListener(l Listener) Option {
+	return optionFunc(func(o *options) {
+		o.listeners = append(o.listeners, l)
+	})
+}
+
+// emit sends the event to the listeners.
+func (o *options) emit(e Event) {
+	for _, l := range o.listeners {
+		l(e)
+	}
+}
@@ -0,0 +1,67 @@
+package alice
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWithListener(t *testing.T) {
+	var events []Event
+	c, err := NewContainerWithOptions([]Module{&M1{}}, WithListener(func(e Event) {
+		events = append(events, e)
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("unexpected error after Close(): %s", err.Error())
+	}
+
+	var kinds []EventKind
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+	}
+	expected := []EventKind{
+		ModuleReflected, GraphBuilt,
+		ProviderCalling, ProviderCalled, InstancePublished,
+		ProviderCalling, ProviderCalled, InstancePublished,
+		ContainerClosed,
+	}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Fatalf("bad events: got %v, expected %v", kinds, expected)
+	}
+
+	if !reflect.DeepEqual(events[1].Order, []string{"M1"}) {
+		t.Errorf("bad order of GraphBuilt: got %v, expected [M1]", events[1].Order)
+	}
+	called := events[3]
+	if called.Module != "M1" || called.Method != "D1" || called.Instance != "D1" || called.Err != nil {
+		t.Errorf("bad ProviderCalled event: %+v", called)
+	}
+	published := events[4]
+	if published.Instance != "D1" || published.Type != reflect.TypeOf((*D1)(nil)).Elem() {
+		t.Errorf("bad InstancePublished event: %+v", published)
+	}
+}
+
+func TestWithListener_ProviderError(t *testing.T) {
+	validateErr := errors.New("invalid")
+	var called []Event
+	_, err := NewContainerWithOptions([]Module{&HookedModule{ValidateErr: validateErr}}, WithLifecycleHooks(),
+		WithListener(func(e Event) {
+			if e.Kind == ProviderCalled {
+				called = append(called, e)
+			}
+		}))
+	if err == nil {
+		t.Fatal("expect error after NewContainer() on failed Validate")
+	}
+
+	if len(called) != 1 {
+		t.Fatalf("bad ProviderCalled events: got %d, expected 1", len(called))
+	}
+	if called[0].Instance != "hooked.Hooked" || !errors.Is(called[0].Err, validateErr) {
+		t.Errorf("bad ProviderCalled event: %+v", called[0])
+	}
+}
@@ -26,3 +26,48 @@ func TestExample(t *testing.T) {
 	// should not panic
 	c.Instance(reflect.TypeOf((*business.WebPageManager)(nil)))
 }
+
+type fakeHTTPClient struct{}
+
+func (client *fakeHTTPClient) Fetch(url string) string {
+	return "fake"
+}
+
+func TestExample_Override(t *testing.T) {
+	fake := &fakeHTTPClient{}
+	c, err := alice.NewContainerWithOptions(
+		[]alice.Module{&module.ConfigModule{}, &module.PersistModule{}, &module.ClientModule{}, &module.BusinessModule{}},
+		alice.WithOverride("HTTPClient", fake))
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainerWithOptions(): %s", err.Error())
+	}
+
+	httpClient := c.InstanceByName("HTTPClient")
+	if httpClient != fake {
+		t.Errorf("bad http client: got %v, expected %v", httpClient, fake)
+	}
+	if len(c.Overrides()) != 1 {
+		t.Errorf("bad overrides: got %v, expected 1 override", c.Overrides())
+	}
+}
+
+func TestExample_Validate(t *testing.T) {
+	err := alice.Validate(
+		&module.ConfigModule{}, &module.PersistModule{}, &module.ClientModule{}, &module.BusinessModule{})
+	if err != nil {
+		t.Errorf("unexpected error after Validate(): %s", err.Error())
+	}
+}
+
+func TestExample_Generated(t *testing.T) {
+	var c alice.Container = module.NewAliceContainer(
+		&module.BusinessModule{}, &module.ClientModule{}, &module.ConfigModule{}, &module.PersistModule{})
+
+	retries := c.InstanceByName("Retries")
+	if retries != 3 {
+		t.Errorf("bad retries: got %v, expected %v", retries, 3)
+	}
+
+	// should not panic
+	c.Instance(reflect.TypeOf((*business.WebPageManager)(nil)))
+}
@@ -0,0 +1,59 @@
+// Code generated by alice generate. DO NOT EDIT.
+
+package module
+
+import (
+	"reflect"
+
+	"github.com/magic003/alice"
+	"github.com/magic003/alice/example/business"
+	"github.com/magic003/alice/example/client"
+	"github.com/magic003/alice/example/persist"
+)
+
+// AliceContainer contains the instances wired by NewAliceContainer. It implements alice.Container.
+type AliceContainer struct {
+	*alice.StaticContainer
+
+	WebPageManager *business.WebPageManager
+	HTTPClient     client.HTTPClient
+	Retries        int
+	Table          string
+	WebPageDao     persist.WebPageDao
+}
+
+var _ alice.Container = (*AliceContainer)(nil)
+
+// NewAliceContainer wires the modules in instantiation order and returns the instances.
+func NewAliceContainer(
+	businessModule *BusinessModule,
+	clientModule *ClientModule,
+	configModule *ConfigModule,
+	persistModule *PersistModule,
+) *AliceContainer {
+	c := &AliceContainer{StaticContainer: alice.NewStaticContainer()}
+
+	// ConfigModule
+	c.Retries = configModule.Retries()
+	c.Add("Retries", reflect.TypeOf((*int)(nil)).Elem(), c.Retries)
+	c.Table = configModule.Table()
+	c.Add("Table", reflect.TypeOf((*string)(nil)).Elem(), c.Table)
+
+	// PersistModule
+	persistModule.Table = c.Table
+	c.WebPageDao = persistModule.WebPageDao()
+	c.Add("WebPageDao", reflect.TypeOf((*persist.WebPageDao)(nil)).Elem(), c.WebPageDao)
+
+	// ClientModule
+	clientModule.Retries = c.Retries
+	c.HTTPClient = clientModule.HTTPClient()
+	c.Add("HTTPClient", reflect.TypeOf((*client.HTTPClient)(nil)).Elem(), c.HTTPClient)
+
+	// BusinessModule
+	businessModule.HTTPClient = c.HTTPClient
+	businessModule.WebPageDao = c.WebPageDao
+	c.WebPageManager = businessModule.WebPageManager()
+	c.Add("WebPageManager", reflect.TypeOf((**business.WebPageManager)(nil)).Elem(), c.WebPageManager)
+
+	return c
+}
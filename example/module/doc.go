@@ -0,0 +1,6 @@
+// Package module defines the modules of the example.
+//
+// alice_gen.go is generated from the modules by the alice command. It wires the same instances without reflection.
+package module
+
+//go:generate go run github.com/magic003/alice/cmd/alice generate .
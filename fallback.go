@@ -0,0 +1,99 @@
+package alice
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const _AliceFallbacksMethodName = "AliceFallbacks"
+
+// FallbackModule is implemented by modules whose providers are fallbacks, e.g. a stock LoggingModule which yields to
+// the loggers brought by applications.
+//
+//	func (m *LoggingModule) AliceFallbacks() []string {
+//		return []string{"Logger"}
+//	}
+//
+// A fallback provider is dropped if another provider which is not a fallback provides an instance with the same
+// qualified name. Otherwise it is kept, but a dependency by type is resolved to a fallback only if no other provider
+// matches the type. Among fallbacks, the first one in the order of modules and providers wins, so fallbacks never make
+// a dependency ambiguous. Fallbacks are resolved after conditions.
+type FallbackModule interface {
+	Module
+	// AliceFallbacks returns the names of the provider methods which are fallbacks.
+	AliceFallbacks() []string
+}
+
+// markFallbacks flags the fallback instances if the module implements FallbackModule. It returns error if a provider
+// is not defined.
+func markFallbacks(m Module, instances []*instanceMethod) error {
+	fm, ok := m.(FallbackModule)
+	if !ok || !declaresHook(m, _AliceFallbacksMethodName) {
+		return nil
+	}
+
+	name := reflect.TypeOf(m).Elem().Name()
+	for _, method := range fm.AliceFallbacks() {
+		instance := findInstance(instances, method)
+		if instance == nil {
+			return fmt.Errorf("fallback provider %s.%s is not defined", name, method)
+		}
+		instance.fallback = true
+	}
+	return nil
+}
+
+// applyFallbacks removes the fallback instances which are replaced by other instances with the same qualified name.
+// Fallbacks of types provided by other instances are kept, since they may still be depended on by names.
+func applyFallbacks(rms []*reflectedModule) {
+	var provided []*providedInstance
+	for _, rm := range rms {
+		for _, instance := range rm.instances {
+			if !instance.fallback {
+				provided = append(provided, &providedInstance{rm: rm, instance: instance})
+			}
+		}
+	}
+
+	for _, rm := range rms {
+		var instances []*instanceMethod
+		for _, instance := range rm.instances {
+			if !instance.fallback {
+				instances = append(instances, instance)
+				continue
+			}
+			if !isReplaced(rm, instance, provided) {
+				instances = append(instances, instance)
+				provided = append(provided, &providedInstance{rm: rm, instance: instance})
+			}
+		}
+		rm.instances = instances
+	}
+}
+
+// isReplaced checks if any of the provided instances has the same qualified name as the fallback instance of the
+// module. Instances with the same unqualified name in other namespaces don't replace it.
+func isReplaced(rm *reflectedModule, fallback *instanceMethod, provided []*providedInstance) bool {
+	name := rm.qualifiedName(fallback.name)
+	for _, p := range provided {
+		if p.hasQualifiedName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// findFallback finds the first fallback instance of the type, or the first one assignable to the type if none is of
+// the exact type. It returns nil if not found.
+func findFallback(fallbacks []*providedInstance, t reflect.Type) *providedInstance {
+	var assignable *providedInstance
+	for _, p := range fallbacks {
+		if p.instance.tp == t {
+			return p
+		}
+		if assignable == nil && p.instance.tp.AssignableTo(t) {
+			assignable = p
+		}
+	}
+	return assignable
+}
@@ -0,0 +1,218 @@
+package alice
+
+import (
+	"reflect"
+	"testing"
+)
+
+type StockD1Module struct {
+	BaseModule
+}
+
+func (m *StockD1Module) AliceFallbacks() []string {
+	return []string{"StockD1"}
+}
+
+func (m *StockD1Module) StockD1() D1 {
+	return &decoratedD1{}
+}
+
+type AnotherStockD1Module struct {
+	BaseModule
+}
+
+func (m *AnotherStockD1Module) AliceFallbacks() []string {
+	return []string{"AnotherStockD1"}
+}
+
+func (m *AnotherStockD1Module) AnotherStockD1() D1 {
+	return &decoratedD1{base: &D1Impl{}}
+}
+
+type TeamD1Module struct {
+	BaseModule
+}
+
+func (m *TeamD1Module) TeamD1() *D1Impl {
+	return &D1Impl{}
+}
+
+type StockServerModule struct {
+	BaseModule
+	*StockD1Module
+}
+
+func (m *StockServerModule) Address() string {
+	return ":8080"
+}
+
+type FallbackDependantModule struct {
+	BaseModule
+	D1 D1 `alice:""`
+}
+
+type undefinedFallbackModule struct {
+	BaseModule
+}
+
+func (m *undefinedFallbackModule) AliceFallbacks() []string {
+	return []string{"D1"}
+}
+
+func TestFallbacks(t *testing.T) {
+	tests := []struct {
+		modules  []Module
+		expected D1
+	}{
+		{[]Module{&StockD1Module{}}, &decoratedD1{}},
+		{[]Module{&StockD1Module{}, &AnotherStockD1Module{}}, &decoratedD1{}},
+		{[]Module{&AnotherStockD1Module{}, &StockD1Module{}}, &decoratedD1{base: &D1Impl{}}},
+		{[]Module{&StockD1Module{}, &TeamD1Module{}}, &D1Impl{}},
+	}
+	for i, test := range tests {
+		dependant := &FallbackDependantModule{}
+		_, err := NewContainer(append(test.modules, dependant)...)
+		if err != nil {
+			t.Fatalf("unexpected error after NewContainer() of case %d: %s", i, err.Error())
+		}
+		if !reflect.DeepEqual(dependant.D1, test.expected) {
+			t.Errorf("bad D1 of case %d: got %v, expected %v", i, dependant.D1, test.expected)
+		}
+	}
+}
+
+type PaymentsFallbackModule struct {
+	BaseModule
+}
+
+func (m *PaymentsFallbackModule) AliceNamespace() string {
+	return "payments"
+}
+
+func (m *PaymentsFallbackModule) AliceFallbacks() []string {
+	return []string{"Client"}
+}
+
+func (m *PaymentsFallbackModule) Client() D1 {
+	return &D1Impl{}
+}
+
+type OrdersModule struct {
+	BaseModule
+}
+
+func (m *OrdersModule) AliceNamespace() string {
+	return "orders"
+}
+
+func (m *OrdersModule) Client() D2 {
+	return &D2Impl{}
+}
+
+type PaymentsUserModule struct {
+	BaseModule
+	Client D1 `alice:"payments.Client"`
+}
+
+func TestFallbacks_Namespaces(t *testing.T) {
+	user := &PaymentsUserModule{}
+	_, err := NewContainer(&PaymentsFallbackModule{}, &OrdersModule{}, user)
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if _, ok := user.Client.(*D1Impl); !ok {
+		t.Errorf("bad payments.Client: got %v, expected the fallback *D1Impl", user.Client)
+	}
+}
+
+type ConcreteStockD1Module struct {
+	BaseModule
+}
+
+func (m *ConcreteStockD1Module) AliceFallbacks() []string {
+	return []string{"ConcreteStockD1"}
+}
+
+func (m *ConcreteStockD1Module) ConcreteStockD1() *decoratedD1 {
+	return &decoratedD1{}
+}
+
+func TestFallbacks_AssignableTypes(t *testing.T) {
+	dependant := &FallbackDependantModule{}
+	c, err := NewContainer(&ConcreteStockD1Module{}, &TeamD1Module{}, dependant)
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(dependant.D1, &D1Impl{}) {
+		t.Errorf("bad D1 replacing fallback of another type: got %v, expected %v", dependant.D1, &D1Impl{})
+	}
+	if d1 := c.Instance(reflect.TypeOf((*D1)(nil)).Elem()); !reflect.DeepEqual(d1, &D1Impl{}) {
+		t.Errorf("bad instance after Instance(): got %v, expected %v", d1, &D1Impl{})
+	}
+	if d1 := c.InstanceByName("ConcreteStockD1"); !reflect.DeepEqual(d1, &decoratedD1{}) {
+		t.Errorf("bad fallback instance after InstanceByName(): got %v, expected %v", d1, &decoratedD1{})
+	}
+}
+
+type TimeoutFallbackModule struct {
+	BaseModule
+}
+
+func (m *TimeoutFallbackModule) AliceFallbacks() []string {
+	return []string{"Timeout"}
+}
+
+func (m *TimeoutFallbackModule) Timeout() int {
+	return 30
+}
+
+type RetriesModule struct {
+	BaseModule
+}
+
+func (m *RetriesModule) Retries() int {
+	return 3
+}
+
+type TimeoutUserModule struct {
+	BaseModule
+	Timeout int `alice:"Timeout"`
+	Retries int `alice:""`
+}
+
+func TestFallbacks_SameTypeOtherName(t *testing.T) {
+	user := &TimeoutUserModule{}
+	_, err := NewContainer(&TimeoutFallbackModule{}, &RetriesModule{}, user)
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if user.Timeout != 30 {
+		t.Errorf("bad Timeout by name of fallback: got %d, expected 30", user.Timeout)
+	}
+	if user.Retries != 3 {
+		t.Errorf("bad int by type: got %d, expected Retries 3 rather than the fallback", user.Retries)
+	}
+}
+
+func TestFallbacks_EmbeddedModule(t *testing.T) {
+	dependant := &FallbackDependantModule{}
+	_, err := NewContainer(&StockServerModule{StockD1Module: &StockD1Module{}}, &TeamD1Module{}, dependant)
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(dependant.D1, &D1Impl{}) {
+		t.Errorf("bad D1 replacing fallback: got %v, expected %v", dependant.D1, &D1Impl{})
+	}
+}
+
+func TestFallbacks_UndefinedProvider(t *testing.T) {
+	_, err := reflectModule(&undefinedFallbackModule{})
+	if err == nil {
+		t.Error("expect error after reflectModule() on undefined fallback provider")
+	}
+	t.Log(err.Error())
+}
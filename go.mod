@@ -0,0 +1,3 @@
+module github.com/magic003/alice
+
+go 1.21
@@ -24,6 +24,17 @@ type graph struct {
 	// g is map representing the dependency graph. Modules in value depend on the key.
 	// Value is a map to avoid duplication.
 	g map[*reflectedModule]map[*reflectedModule]bool
+	// edges are the dependencies between fields and instances, in the order of modules and fields.
+	edges []*dependencyEdge
+}
+
+// dependencyEdge is the dependency from a field of a module to the instance assigned to it.
+type dependencyEdge struct {
+	provider  *reflectedModule
+	instance  *instanceMethod
+	dependant *reflectedModule
+	fieldName string
+	named     bool
 }
 
 // moduleSlice is a container of reflected module slice.
@@ -68,8 +79,9 @@ func (g *graph) dfs(
 	}
 
 	recVisited[m] = true
-	for dependant := range g.g[m] {
-		if !visited[dependant] {
+	// dependants are visited in the order of modules rather than the map, so the order is deterministic.
+	for _, dependant := range g.modules {
+		if g.g[m][dependant] && !visited[dependant] {
 			if err := g.dfs(dependant, visited, stack, recVisited, recPath); err != nil {
 				return err
 			}
@@ -86,19 +98,26 @@ func (g *graph) dfs(
 
 // constructGraph constructs a graph based on the dependency of the modules.
 func (g *graph) constructGraph() error {
-	nameToProviderMap, typeToProvidersMap, err := g.computeProviders()
+	names, typeToProvidersMap, fallbacks, err := g.computeProviders()
 	if err != nil {
 		return err
 	}
 
 	// construct dependency graph
 	for _, rm := range g.modules {
-		if err := g.createDependenciesByNames(rm, nameToProviderMap); err != nil {
+		if err := g.createDependenciesByNames(rm, names); err != nil {
+			return err
+		}
+		if err := g.createDependenciesByTypes(rm, typeToProvidersMap, fallbacks); err != nil {
 			return err
 		}
-		if err := g.createDependenciesByTypes(rm, typeToProvidersMap); err != nil {
+		if err := g.createDecorations(rm, names); err != nil {
 			return err
 		}
+		// overriding providers may call the overridden ones of the base modules.
+		for _, base := range rm.bases {
+			g.addDependencyEdge(base, rm)
+		}
 		if _, ok := g.g[rm]; !ok {
 			g.g[rm] = make(map[*reflectedModule]bool)
 		}
@@ -107,24 +126,27 @@ func (g *graph) constructGraph() error {
 	return nil
 }
 
-// computeProviders figures out instance names and types, and the corresponding modules that provide them.
+// computeProviders figures out instance names and types, and the corresponding modules that provide them. Fallback
+// instances are returned separately in the order of modules and providers.
 func (g *graph) computeProviders() (
-	map[string]*reflectedModule,
+	*nameIndex,
 	map[reflect.Type][]*reflectedModule,
+	[]*providedInstance,
 	error) {
 
-	nameToProviderMap := make(map[string]*reflectedModule)
+	names := newNameIndex()
 	typeToProvidersMap := make(map[reflect.Type][]*reflectedModule)
+	var fallbacks []*providedInstance
 
 	for _, provider := range g.modules {
+		if err := names.add(provider); err != nil {
+			return nil, nil, nil, err
+		}
 		for _, instance := range provider.instances {
-			name := instance.name
-			if existingProvider, ok := nameToProviderMap[name]; ok {
-				return nil, nil,
-					fmt.Errorf("duplicated name %s in module %s and %s", name, existingProvider.name, provider.name)
+			if instance.fallback {
+				fallbacks = append(fallbacks, &providedInstance{rm: provider, instance: instance})
+				continue
 			}
-			nameToProviderMap[name] = provider
-
 			t := instance.tp
 			existingProviders, _ := typeToProvidersMap[t]
 			existingProviders = append(existingProviders, provider)
@@ -132,26 +154,53 @@ func (g *graph) computeProviders() (
 		}
 	}
 
-	return nameToProviderMap, typeToProvidersMap, nil
+	return names, typeToProvidersMap, fallbacks, nil
 }
 
 // createDependenciesByNames creates dependencies of a module using its named dependencies.
-func (g *graph) createDependenciesByNames(rm *reflectedModule, nameToProviderMap map[string]*reflectedModule) error {
+func (g *graph) createDependenciesByNames(rm *reflectedModule, names *nameIndex) error {
 	for _, depField := range rm.namedDepends {
 		depName := depField.name
-		provider, ok := nameToProviderMap[depName]
-		if !ok {
+		provider, instance, candidates := names.lookup(depName)
+		if len(candidates) > 1 {
+			return fmt.Errorf("dependency name %s.%s is ambiguous, use one of the qualified names %v",
+				rm.name, depName, candidates)
+		}
+		if provider == nil {
 			return fmt.Errorf("dependency name %s.%s is not found", rm.name, depName)
 		}
+		if !assignableDependency(instance.tp, depField.field.Type()) {
+			return fmt.Errorf("dependency name %s.%s of type %s is not assignable from %s.%s of type %s",
+				rm.name, depName, depField.field.Type(), provider.name, instance.name, instance.tp)
+		}
 		g.addDependencyEdge(provider, rm)
+		g.edges = append(g.edges, &dependencyEdge{
+			provider:  provider,
+			instance:  instance,
+			dependant: rm,
+			fieldName: depField.fieldName,
+			named:     true,
+		})
 	}
 
 	return nil
 }
 
-// createDependenciesByTypes creates dependencies of a module using its typed dependencies.
+// assignableDependency checks if an instance of type t could be assigned to a field of fieldType. An instance of an
+// interface type is accepted for a field of a concrete type implementing the interface, e.g. a provider returning D for
+// a field of type *DImpl, because its dynamic type could be the field type. Its value is checked when the field is
+// assigned.
+func assignableDependency(t reflect.Type, fieldType reflect.Type) bool {
+	if t.AssignableTo(fieldType) {
+		return true
+	}
+	return t.Kind() == reflect.Interface && fieldType.Kind() != reflect.Interface && fieldType.Implements(t)
+}
+
+// createDependenciesByTypes creates dependencies of a module using its typed dependencies. A dependency is resolved to
+// a fallback instance only if no other instance matches its type.
 func (g *graph) createDependenciesByTypes(
-	rm *reflectedModule, typeToProvidersMap map[reflect.Type][]*reflectedModule) error {
+	rm *reflectedModule, typeToProvidersMap map[reflect.Type][]*reflectedModule, fallbacks []*providedInstance) error {
 	for _, depField := range rm.typedDepends {
 		depType := depField.tp
 		providers, ok := typeToProvidersMap[depType]
@@ -162,6 +211,13 @@ func (g *graph) createDependenciesByTypes(
 			}
 			providers = assignableProviders
 		}
+		var instance *instanceMethod
+		if len(providers) == 0 {
+			if fallback := findFallback(fallbacks, depType); fallback != nil {
+				providers = []*reflectedModule{fallback.rm}
+				instance = fallback.instance
+			}
+		}
 
 		if len(providers) == 0 {
 			return fmt.Errorf("dependency type %s.%s is not found", rm.name, depType.Name())
@@ -174,7 +230,16 @@ func (g *graph) createDependenciesByTypes(
 			return fmt.Errorf("dependency type %s.%s is found in mutiple modules: %s",
 				rm.name, depType.Name(), names)
 		}
+		if instance == nil {
+			instance = providers[0].instanceOfType(depType)
+		}
 		g.addDependencyEdge(providers[0], rm)
+		g.edges = append(g.edges, &dependencyEdge{
+			provider:  providers[0],
+			instance:  instance,
+			dependant: rm,
+			fieldName: depField.fieldName,
+		})
 	}
 
 	return nil
@@ -204,6 +269,16 @@ func (g *graph) findAssignableProviders(
 	return providers, nil
 }
 
+// edgeOf returns the dependency edge of a field of the dependant module, or nil if the field is not a dependency.
+func (g *graph) edgeOf(dependant *reflectedModule, fieldName string) *dependencyEdge {
+	for _, edge := range g.edges {
+		if edge.dependant == dependant && edge.fieldName == fieldName {
+			return edge
+		}
+	}
+	return nil
+}
+
 // addDependencyEdge creates a dependency edge in the graph. dependant depends on parent.
 func (g *graph) addDependencyEdge(parent *reflectedModule, dependant *reflectedModule) {
 	dependants, ok := g.g[parent]
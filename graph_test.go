@@ -66,6 +66,19 @@ func TestConstructGraph_NameNotFound(t *testing.T) {
 	t.Log(err.Error())
 }
 
+func TestConstructGraph_NameTypeNotAssignable(t *testing.T) {
+	var (
+		m1, _ = reflectModule(&M1{})
+		m2, _ = reflectModule(&MismatchedNameTypeModule{})
+	)
+	_, err := createGraph(m1, m2)
+
+	if err == nil {
+		t.Error("expect error after createGraph() of named dependency type not assignable")
+	}
+	t.Log(err.Error())
+}
+
 func TestConstructGraph_MultipleAssignableTypes(t *testing.T) {
 	var (
 		m1, _ = reflectModule(&M3{})
@@ -0,0 +1,48 @@
+package alice
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// _InstancesModuleName is the name of the module holding instances added by WithInstance.
+const _InstancesModuleName = "instances"
+
+// WithInstance adds an instance which is not provided by any module, e.g. a value created before the container, or a
+// stub in tests. It could be injected into modules by name or by type, the same as instances provided by modules.
+// The instance type is the dynamic type of the instance.
+func WithInstance(name string, instance interface{}) Option {
+	return optionFunc(func(o *options) {
+		o.instances = append(o.instances, &namedInstance{
+			name:     name,
+			instance: instance,
+		})
+	})
+}
+
+// instancesModule creates a reflectedModule which provides the instances added by WithInstance. It returns error if
+// any of the instance is nil.
+func instancesModule(instances []*namedInstance) (*reflectedModule, error) {
+	var methods []*instanceMethod
+	for _, ni := range instances {
+		v := reflect.ValueOf(ni.instance)
+		if !v.IsValid() {
+			return nil, fmt.Errorf("instance %s is nil", ni.name)
+		}
+		methods = append(methods, &instanceMethod{
+			name:       ni.name,
+			methodName: ni.name,
+			tp:         v.Type(),
+			supplied:   true,
+			method: reflect.MakeFunc(reflect.FuncOf(nil, []reflect.Type{v.Type()}, false),
+				func([]reflect.Value) []reflect.Value {
+					return []reflect.Value{v}
+				}),
+		})
+	}
+
+	return &reflectedModule{
+		name:      _InstancesModuleName,
+		instances: methods,
+	}, nil
+}
@@ -0,0 +1,29 @@
+package alice
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPopulate_WithInstance(t *testing.T) {
+	m4 := &M4{}
+	d1 := &D1Impl{}
+
+	c := newContainer([]Module{m4}, []Option{WithInstance("D1", d1)})
+	c.populate()
+
+	if m4.D1 != d1 {
+		t.Errorf("bad m4.D1 after populate() with instance: got %v, expected %v", m4.D1, d1)
+	}
+	if instance := c.Instance(reflect.TypeOf(d1)); instance != d1 {
+		t.Errorf("bad instance from Instance() with instance: got %v, expected %v", instance, d1)
+	}
+}
+
+func TestInstancesModule_NilInstance(t *testing.T) {
+	_, err := instancesModule([]*namedInstance{{name: "D1"}})
+	if err == nil {
+		t.Error("expect error after instancesModule() on nil instance")
+	}
+	t.Log(err.Error())
+}
@@ -0,0 +1,74 @@
+package alice
+
+import (
+	"fmt"
+)
+
+// Initializer is implemented by instances which need to be initialized after they are provided. Init is called only
+// if WithLifecycleHooks is set.
+type Initializer interface {
+	Init() error
+}
+
+// Validator is implemented by instances which check their wiring after they are provided, e.g. a DAO with a non-empty
+// table name. Validate is called after Init, only if WithLifecycleHooks is set.
+type Validator interface {
+	Validate() error
+}
+
+// WithLifecycleHooks makes the container call Init and then Validate on each instance implementing Initializer or
+// Validator, right after its provider returns and before it is decorated or published. A failure aborts the build
+// with a *HookError. The hooks are opt-in, so types which use those method names for something else are not affected.
+func WithLifecycleHooks() Option {
+	return optionFunc(func(o *options) {
+		o.lifecycleHooks = true
+	})
+}
+
+// HookError is returned when the Init or Validate method of an instance fails.
+type HookError struct {
+	// Module is the name of the module providing the instance.
+	Module string
+	// Method is the name of the provider method.
+	Method string
+	// Instance is the qualified name of the instance.
+	Instance string
+	// Hook is the name of the failed method, either "Init" or "Validate".
+	Hook string
+	// Err is the error returned by the hook.
+	Err error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("%s of instance %s provided by %s.%s failed: %s",
+		e.Hook, e.Instance, e.Module, e.Method, e.Err.Error())
+}
+
+// Unwrap returns the error returned by the hook.
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// runLifecycleHooks calls Init and Validate on the instance if implemented. It returns *HookError if any fails.
+func runLifecycleHooks(rm *reflectedModule, im *instanceMethod, instance interface{}) error {
+	hookError := func(hook string, err error) error {
+		return &HookError{
+			Module:   rm.name,
+			Method:   im.methodName,
+			Instance: rm.qualifiedName(im.name),
+			Hook:     hook,
+			Err:      err,
+		}
+	}
+	if initializer, ok := instance.(Initializer); ok {
+		if err := initializer.Init(); err != nil {
+			return hookError("Init", err)
+		}
+	}
+	if validator, ok := instance.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return hookError("Validate", err)
+		}
+	}
+	return nil
+}
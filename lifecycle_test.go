@@ -0,0 +1,79 @@
+package alice
+
+import (
+	"errors"
+	"testing"
+)
+
+type hookedInstance struct {
+	calls       []string
+	validateErr error
+}
+
+func (h *hookedInstance) Init() error {
+	h.calls = append(h.calls, "Init")
+	return nil
+}
+
+func (h *hookedInstance) Validate() error {
+	h.calls = append(h.calls, "Validate")
+	return h.validateErr
+}
+
+type HookedModule struct {
+	BaseModule
+	ValidateErr error
+}
+
+func (m *HookedModule) AliceNamespace() string {
+	return "hooked"
+}
+
+func (m *HookedModule) Hooked() *hookedInstance {
+	return &hookedInstance{validateErr: m.ValidateErr}
+}
+
+func TestWithLifecycleHooks(t *testing.T) {
+	c, err := NewContainerWithOptions([]Module{&HookedModule{}}, WithLifecycleHooks())
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	calls := c.InstanceByName("Hooked").(*hookedInstance).calls
+	if len(calls) != 2 || calls[0] != "Init" || calls[1] != "Validate" {
+		t.Errorf("bad hooks called: got %v, expected [Init Validate]", calls)
+	}
+}
+
+func TestWithLifecycleHooks_Disabled(t *testing.T) {
+	c, err := NewContainer(&HookedModule{ValidateErr: errors.New("invalid")})
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if calls := c.InstanceByName("Hooked").(*hookedInstance).calls; len(calls) != 0 {
+		t.Errorf("bad hooks called without WithLifecycleHooks(): got %v, expected none", calls)
+	}
+}
+
+func TestWithLifecycleHooks_Error(t *testing.T) {
+	validateErr := errors.New("empty table name")
+	_, err := NewContainerWithOptions([]Module{&HookedModule{ValidateErr: validateErr}}, WithLifecycleHooks())
+	if err == nil {
+		t.Fatal("expect error after NewContainer() on failed Validate")
+	}
+	t.Log(err.Error())
+
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) {
+		t.Fatalf("bad error type after NewContainer(): got %T, expected *HookError", err)
+	}
+	expected := HookError{Module: "HookedModule", Method: "Hooked", Instance: "hooked.Hooked", Hook: "Validate",
+		Err: validateErr}
+	if *hookErr != expected {
+		t.Errorf("bad HookError: got %+v, expected %+v", *hookErr, expected)
+	}
+	if !errors.Is(err, validateErr) {
+		t.Errorf("bad unwrapped error: got %v, expected %v", errors.Unwrap(err), validateErr)
+	}
+}
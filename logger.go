@@ -0,0 +1,52 @@
+package alice
+
+import (
+	"log/slog"
+)
+
+// WithLogger logs at debug level what the container is doing while it is built: each module reflected, the
+// instantiation order, each provider call with its duration, each dependency assigned to a field, and each
+// unqualified name or interface type resolved to an instance. It helps to find out which provider hangs or fails
+// during a large startup:
+//
+//	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+//	c, err := alice.NewContainerWithOptions(modules, alice.WithLogger(logger))
+//
+// Warnings, such as the use of deprecated aliases, are logged at warn level instead of by the function set by WithLogf.
+func WithLogger(logger *slog.Logger) Option {
+	return optionFunc(func(o *options) {
+		o.logger = logger
+		o.listeners = append(o.listeners, logEvent(logger))
+	})
+}
+
+// logEvent returns a listener logging the events at debug level.
+func logEvent(logger *slog.Logger) Listener {
+	return func(e Event) {
+		switch e.Kind {
+		case ModuleReflected:
+			logger.Debug("alice: module reflected", "module", e.Module)
+		case GraphBuilt:
+			logger.Debug("alice: instantiation order computed", "order", e.Order)
+		case ProviderCalling:
+			logger.Debug("alice: calling provider", "module", e.Module, "method", e.Method, "instance", e.Instance,
+				"attempt", e.Attempt)
+		case ProviderCalled:
+			args := []interface{}{"module", e.Module, "method", e.Method, "instance", e.Instance, "attempt", e.Attempt,
+				"duration", e.Duration}
+			if e.Err != nil {
+				args = append(args, "error", e.Err)
+			}
+			logger.Debug("alice: provider called", args...)
+		case ContainerClosed:
+			logger.Debug("alice: container closed")
+		}
+	}
+}
+
+// debug logs the message at debug level if a logger is set by WithLogger.
+func (o *options) debug(msg string, args ...interface{}) {
+	if o.logger != nil {
+		o.logger.Debug(msg, args...)
+	}
+}
@@ -0,0 +1,75 @@
+package alice
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c, err := NewContainerWithOptions([]Module{&M1{}, &M2{}, &M3{}, &M4{}}, WithLogger(logger))
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+	c.Close()
+
+	logs := buf.String()
+	t.Log(logs)
+	for _, expected := range []string{
+		`msg="alice: module reflected" module=M1`,
+		`msg="alice: instantiation order computed" order="[M1 M4 M2 M3]"`,
+		`msg="alice: calling provider" module=M4 method=D3 instance=D3`,
+		`msg="alice: provider called" module=M1 method=D1 instance=D1 attempt=1 duration=`,
+		`msg="alice: dependency assigned" module=M4 field=D1 name=D1`,
+		`msg="alice: dependency assigned" module=M3 field=D5 type=alice.D5 name=D5`,
+		`msg="alice: type resolved to an assignable instance" type=alice.D5 instance=*alice.D5Impl`,
+		`msg="alice: container closed"`,
+	} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("missing log: %s", expected)
+		}
+	}
+}
+
+func TestWithLogger_Info(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	if _, err := NewContainerWithOptions([]Module{&M1{}}, WithLogger(logger)); err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("bad logs above debug level: got %s, expected none", buf.String())
+	}
+}
+
+func TestWithLogger_Warn(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	logfCalled := false
+	logf := func(format string, args ...interface{}) {
+		logfCalled = true
+	}
+	c, err := NewContainerWithOptions([]Module{&RenamedModule{}, &OldNameModule{}}, WithLogf(logf), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+	c.InstanceByName("OldD1")
+
+	logs := buf.String()
+	t.Log(logs)
+	for _, expected := range []string{
+		`level=WARN msg="alice: dependency name is a deprecated alias" name=OldNameModule.OldD1 use=D1`,
+		`level=WARN msg="alice: instance name is a deprecated alias" name=OldD1 use=D1`,
+	} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("missing log: %s", expected)
+		}
+	}
+	if logfCalled {
+		t.Error("bad warning logged by WithLogf when a logger is set by WithLogger")
+	}
+}
@@ -0,0 +1,56 @@
+package alice
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExportMermaid renders the dependency graph of the modules as a Mermaid flowchart, which could be embedded in
+// Markdown documents. Modules are rendered as subgraphs and instances as nodes in them. Each edge goes from an instance
+// to the module whose field it is assigned to, and is labeled with the field name. Edges of fields associated by name
+// are solid, and those associated by type are dotted. No provider method is called. It returns error if the modules
+// are invalid, the same as Validate.
+func ExportMermaid(w io.Writer, modules ...Module) error {
+	d, err := DescribeGraph(modules...)
+	if err != nil {
+		return err
+	}
+	return d.WriteMermaid(w)
+}
+
+// WriteMermaid writes the graph description as a Mermaid flowchart. See ExportMermaid for how the graph is rendered.
+func (d *GraphDescription) WriteMermaid(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	moduleIDs := make(map[string]string)
+	instanceIDs := make(map[string]string)
+
+	fmt.Fprintln(bw, "flowchart LR")
+	for i, md := range d.Modules {
+		id := fmt.Sprintf("m%d", i)
+		moduleIDs[md.ID] = id
+		fmt.Fprintf(bw, "\tsubgraph %s [%s]\n", id, mermaidQuote(md.Name))
+		for _, instance := range md.Instances {
+			instanceID := fmt.Sprintf("i%d", len(instanceIDs))
+			instanceIDs[instance.Name] = instanceID
+			fmt.Fprintf(bw, "\t\t%s[%s]\n", instanceID, mermaidQuote(instance.Name+"<br/>"+instance.Type))
+		}
+		fmt.Fprintln(bw, "\tend")
+	}
+	for _, edge := range d.Edges {
+		arrow := "-.->"
+		if edge.Kind == FieldKindName {
+			arrow = "-->"
+		}
+		fmt.Fprintf(bw, "\t%s %s|%s| %s\n",
+			instanceIDs[edge.From], arrow, mermaidQuote(edge.Field), moduleIDs[edge.To])
+	}
+
+	return bw.Flush()
+}
+
+// mermaidQuote returns a quoted Mermaid string. Quotes are replaced by the entity code.
+func mermaidQuote(s string) string {
+	return `"` + strings.Replace(s, `"`, "#quot;", -1) + `"`
+}
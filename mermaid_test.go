@@ -0,0 +1,43 @@
+package alice
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExportMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportMermaid(&buf, &M1{}, &M3{}, &M4{}, &ModuleWithD5Impl1{}); err != nil {
+		t.Errorf("unexpected error after ExportMermaid(): %s", err.Error())
+	}
+
+	expected := `flowchart LR
+	subgraph m0 ["M1"]
+		i0["D1<br/>alice.D1"]
+		i1["D2<br/>alice.D2"]
+	end
+	subgraph m1 ["M3"]
+		i2["DM3<br/>alice.D1"]
+	end
+	subgraph m2 ["M4"]
+		i3["D3<br/>alice.D3"]
+		i4["D4<br/>alice.D4"]
+	end
+	subgraph m3 ["ModuleWithD5Impl1"]
+		i5["D5_1<br/>*alice.D5Impl"]
+	end
+	i5 -.->|"D5"| m1
+	i0 -->|"D1"| m2
+`
+	if buf.String() != expected {
+		t.Errorf("bad Mermaid after ExportMermaid(): got\n%s\nexpected\n%s", buf.String(), expected)
+	}
+}
+
+func TestMermaidQuote(t *testing.T) {
+	quoted := mermaidQuote(`a"b`)
+	expected := `"a#quot;b"`
+	if quoted != expected {
+		t.Errorf("bad quoted string after mermaidQuote(): got %s, expected %s", quoted, expected)
+	}
+}
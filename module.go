@@ -27,3 +27,15 @@ type BaseModule struct{}
 func (b *BaseModule) IsModule() bool {
 	return true
 }
+
+// IgnoringModule is implemented by modules which have exported methods that are not providers. Methods promoted from
+// embedded fields are never providers, so they don't need to be ignored.
+//
+//	func (m *ExampleModule) AliceIgnore() []string {
+//		return []string{"Format"}
+//	}
+type IgnoringModule interface {
+	Module
+	// AliceIgnore returns the names of exported methods which are not providers.
+	AliceIgnore() []string
+}
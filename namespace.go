@@ -0,0 +1,146 @@
+package alice
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+const _AliceNamespaceMethodName = "AliceNamespace"
+
+// NamespacedModule is implemented by modules which put their instances in a namespace, so that modules of different
+// teams could provide instances with the same names. An instance in a namespace is addressed by the qualified name,
+// e.g. "payments.Client", in tags and InstanceByName. The unqualified name "Client" works as well, as long as no other
+// module provides an instance named "Client".
+//
+//	func (m *PaymentsModule) AliceNamespace() string {
+//		return "payments"
+//	}
+type NamespacedModule interface {
+	Module
+	// AliceNamespace returns the namespace of the instances provided by the module. It must be a valid identifier.
+	AliceNamespace() string
+}
+
+// moduleNamespace returns the namespace of the module if it implements NamespacedModule and declares AliceNamespace
+// itself. The module is a pointer of struct. It returns error if the namespace is not a valid identifier.
+func moduleNamespace(m Module) (string, error) {
+	nm, ok := m.(NamespacedModule)
+	if !ok || !declaresHook(m, _AliceNamespaceMethodName) {
+		return "", nil
+	}
+	namespace := nm.AliceNamespace()
+	if !token.IsIdentifier(namespace) {
+		return "", fmt.Errorf("namespace %q of module %s is not a valid identifier",
+			namespace, reflect.TypeOf(m).Elem().Name())
+	}
+	return namespace, nil
+}
+
+// qualifiedName returns the name of an instance qualified by the namespace of the module.
+func (rm *reflectedModule) qualifiedName(name string) string {
+	if rm.namespace == "" {
+		return name
+	}
+	return rm.namespace + "." + name
+}
+
+// removeQualifiedInstance removes the instance with the qualified name from the module. It returns false if the
+// module doesn't provide such an instance.
+func (rm *reflectedModule) removeQualifiedInstance(name string) bool {
+	for _, instance := range rm.instances {
+		if rm.qualifiedName(instance.name) == name {
+			return rm.removeInstance(instance.methodName)
+		}
+	}
+	return false
+}
+
+// unqualifiedName returns the name without the namespace, or an empty string if the name is not qualified.
+func unqualifiedName(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
+
+// nameIndex finds the instances by qualified names, or by unqualified names if they are unambiguous. Aliases are
+// indexed the same as instance names.
+type nameIndex struct {
+	qualified   map[string]*reflectedModule
+	unqualified map[string][]string
+	instances   map[string]*instanceMethod
+}
+
+func newNameIndex() *nameIndex {
+	return &nameIndex{
+		qualified:   make(map[string]*reflectedModule),
+		unqualified: make(map[string][]string),
+		instances:   make(map[string]*instanceMethod),
+	}
+}
+
+// add adds the instances of the modules. It returns error if a qualified name is provided by multiple modules.
+func (idx *nameIndex) add(rms ...*reflectedModule) error {
+	for _, rm := range rms {
+		for _, instance := range rm.instances {
+			if err := idx.addName(rm, instance, rm.qualifiedName(instance.name)); err != nil {
+				return err
+			}
+			for _, alias := range instance.aliases {
+				if err := idx.addName(rm, instance, rm.qualifiedName(alias.Name)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addName adds a qualified name of an instance.
+func (idx *nameIndex) addName(rm *reflectedModule, instance *instanceMethod, name string) error {
+	if existing, ok := idx.qualified[name]; ok {
+		return duplicatedNameError(name, existing, rm)
+	}
+	idx.qualified[name] = rm
+	idx.instances[name] = instance
+	if short := unqualifiedName(name); short != "" {
+		idx.unqualified[short] = append(idx.unqualified[short], name)
+	}
+	return nil
+}
+
+// lookup finds the module and the instance by the name. If the name is unqualified and ambiguous, it returns the
+// qualified names as candidates.
+func (idx *nameIndex) lookup(name string) (*reflectedModule, *instanceMethod, []string) {
+	if rm, ok := idx.qualified[name]; ok {
+		return rm, idx.instances[name], nil
+	}
+	candidates := idx.unqualified[name]
+	if len(candidates) == 1 {
+		return idx.qualified[candidates[0]], idx.instances[candidates[0]], nil
+	}
+	return nil, nil, candidates
+}
+
+// duplicatedNameError returns the error of a name provided by two modules. It suggests qualified names in namespaces
+// derived from the module names.
+func duplicatedNameError(name string, rm1, rm2 *reflectedModule) error {
+	short := name
+	if s := unqualifiedName(name); s != "" {
+		short = s
+	}
+	return fmt.Errorf("duplicated name %s in module %s and %s; declare different namespaces with %s() and use "+
+		"qualified names, e.g. %s.%s and %s.%s", name, rm1.name, rm2.name, _AliceNamespaceMethodName,
+		suggestedNamespace(rm1), short, suggestedNamespace(rm2), short)
+}
+
+// suggestedNamespace returns a namespace for the module derived from its name, e.g. "payments" for PaymentsModule.
+func suggestedNamespace(rm *reflectedModule) string {
+	name := strings.TrimSuffix(rm.name, "Module")
+	if name == "" {
+		name = rm.name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
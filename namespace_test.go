@@ -0,0 +1,149 @@
+package alice
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type PaymentsModule struct {
+	BaseModule
+}
+
+func (m *PaymentsModule) AliceNamespace() string {
+	return "payments"
+}
+
+func (m *PaymentsModule) Client() D1 {
+	return &D1Impl{}
+}
+
+func (m *PaymentsModule) Gateway() D2 {
+	return &D2Impl{}
+}
+
+type ShippingModule struct {
+	BaseModule
+}
+
+func (m *ShippingModule) AliceNamespace() string {
+	return "shipping"
+}
+
+func (m *ShippingModule) Client() D3 {
+	return &D3Impl{}
+}
+
+type CheckoutModule struct {
+	BaseModule
+	Payments D1 `alice:"payments.Client"`
+	Shipping D3 `alice:"shipping.Client"`
+	Gateway  D2 `alice:"Gateway"`
+}
+
+type AmbiguousCheckoutModule struct {
+	BaseModule
+	Client D1 `alice:"Client"`
+}
+
+type AppModule struct {
+	BaseModule
+	*PaymentsModule
+}
+
+func (m *AppModule) Config() D3 {
+	return &D3Impl{}
+}
+
+type invalidNamespaceModule struct {
+	BaseModule
+}
+
+func (m *invalidNamespaceModule) AliceNamespace() string {
+	return "pay.ments"
+}
+
+func TestNamespace(t *testing.T) {
+	checkout := &CheckoutModule{}
+	c, err := NewContainer(&PaymentsModule{}, &ShippingModule{}, checkout)
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(checkout.Payments, &D1Impl{}) {
+		t.Errorf("bad field assigned by qualified name: got %v, expected %v", checkout.Payments, &D1Impl{})
+	}
+	if !reflect.DeepEqual(checkout.Shipping, &D3Impl{}) {
+		t.Errorf("bad field assigned by qualified name: got %v, expected %v", checkout.Shipping, &D3Impl{})
+	}
+	if !reflect.DeepEqual(checkout.Gateway, &D2Impl{}) {
+		t.Errorf("bad field assigned by unqualified name: got %v, expected %v", checkout.Gateway, &D2Impl{})
+	}
+
+	if d3 := c.InstanceByName("shipping.Client"); !reflect.DeepEqual(d3, &D3Impl{}) {
+		t.Errorf("bad instance after InstanceByName(): got %v, expected %v", d3, &D3Impl{})
+	}
+	if d2 := c.InstanceByName("Gateway"); !reflect.DeepEqual(d2, &D2Impl{}) {
+		t.Errorf("bad instance after InstanceByName(): got %v, expected %v", d2, &D2Impl{})
+	}
+}
+
+func TestNamespace_EmbeddedModule(t *testing.T) {
+	c, err := NewContainer(&AppModule{PaymentsModule: &PaymentsModule{}})
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if d3 := c.InstanceByName("Config"); !reflect.DeepEqual(d3, &D3Impl{}) {
+		t.Errorf("bad instance after InstanceByName(): got %v, expected %v", d3, &D3Impl{})
+	}
+	if d1 := c.InstanceByName("payments.Client"); !reflect.DeepEqual(d1, &D1Impl{}) {
+		t.Errorf("bad instance after InstanceByName(): got %v, expected %v", d1, &D1Impl{})
+	}
+	if _, ok := c.(*container).instanceByName["payments.Config"]; ok {
+		t.Error("instance of AppModule is put in the namespace of the embedded PaymentsModule")
+	}
+}
+
+func TestNamespace_PanicOnAmbiguousName(t *testing.T) {
+	c := CreateContainer(&PaymentsModule{}, &ShippingModule{})
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Error("expect InstanceByName() to panic on ambiguous name")
+		}
+		t.Log(r)
+	}()
+	c.InstanceByName("Client")
+}
+
+func TestNamespace_AmbiguousDependency(t *testing.T) {
+	_, err := NewContainer(&PaymentsModule{}, &ShippingModule{}, &AmbiguousCheckoutModule{})
+	if err == nil {
+		t.Fatal("expect error after NewContainer() on ambiguous dependency name")
+	}
+	expected := "use one of the qualified names [payments.Client shipping.Client]"
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("bad error after NewContainer(): got %q, expected to contain %q", err.Error(), expected)
+	}
+}
+
+func TestNamespace_DuplicatedName(t *testing.T) {
+	_, err := NewContainer(&M1{}, &M1Duplicated{})
+	if err == nil {
+		t.Fatal("expect error after NewContainer() on duplicated name")
+	}
+	expected := "e.g. m1.D1 and m1Duplicated.D1"
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("bad error after NewContainer(): got %q, expected to contain %q", err.Error(), expected)
+	}
+}
+
+func TestNamespace_InvalidNamespace(t *testing.T) {
+	_, err := reflectModule(&invalidNamespaceModule{})
+	if err == nil {
+		t.Error("expect error after reflectModule() on module with invalid namespace")
+	}
+	t.Log(err.Error())
+}
@@ -0,0 +1,136 @@
+package alice
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+const _AliceNamesMethodName = "AliceNames"
+
+// NamingStrategy derives the name of an instance from the name of the module and the name of the provider method. It
+// is set by WithNamingStrategy.
+type NamingStrategy func(module string, method string) string
+
+// WithNamingStrategy sets how instance names are derived from provider methods. By default, an instance is named
+// after its provider method. The strategy doesn't apply to instances named explicitly by AliceNames, nor to instances
+// added by WithInstance.
+//
+//	c, err := alice.NewContainerWithOptions([]alice.Module{&ClientModule{}}, alice.WithNamingStrategy(alice.SnakeCase))
+func WithNamingStrategy(strategy NamingStrategy) Option {
+	return optionFunc(func(o *options) {
+		o.namingStrategy = strategy
+	})
+}
+
+// LowerFirst names an instance after its provider method with the leading upper case letters lowered, except the
+// first letter of the next word, e.g. "httpClient" for HTTPClient.
+func LowerFirst(module string, method string) string {
+	r := []rune(method)
+	for i := 0; i < len(r) && unicode.IsUpper(r[i]); i++ {
+		if i > 0 && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
+
+// ModulePrefixed names an instance after its provider method prefixed by the module name without the "Module" suffix,
+// e.g. "ClientHTTPClient" for ClientModule.HTTPClient.
+func ModulePrefixed(module string, method string) string {
+	prefix := strings.TrimSuffix(module, "Module")
+	if prefix == "" {
+		prefix = module
+	}
+	return prefix + method
+}
+
+// SnakeCase names an instance after its provider method in snake case, e.g. "http_client" for HTTPClient.
+func SnakeCase(module string, method string) string {
+	r := []rune(method)
+	var b strings.Builder
+	for i, c := range r {
+		if i > 0 && unicode.IsUpper(c) &&
+			(!unicode.IsUpper(r[i-1]) || i+1 < len(r) && unicode.IsLower(r[i+1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+	return b.String()
+}
+
+// NamingModule is implemented by modules which name some of their instances explicitly, e.g. to match the names used
+// in config files while keeping the method names idiomatic. Aliases in AliceAliases are still keyed by the method
+// names.
+//
+//	func (m *ExampleModule) AliceNames() map[string]string {
+//		return map[string]string{
+//			"HTTPClient": "http-client",
+//		}
+//	}
+type NamingModule interface {
+	Module
+	// AliceNames returns the names of instances, keyed by the provider method names.
+	AliceNames() map[string]string
+}
+
+// applyNames sets the names of the instances if the module implements NamingModule. It returns error if a provider is
+// not defined, or a name is invalid.
+func applyNames(m Module, instances []*instanceMethod) error {
+	nm, ok := m.(NamingModule)
+	if !ok || !declaresHook(m, _AliceNamesMethodName) {
+		return nil
+	}
+
+	name := reflect.TypeOf(m).Elem().Name()
+	for method, instanceName := range nm.AliceNames() {
+		instance := findInstance(instances, method)
+		if instance == nil {
+			return fmt.Errorf("named provider %s.%s is not defined", name, method)
+		}
+		if !isInstanceName(instanceName) {
+			return fmt.Errorf("name %q of %s.%s is not a valid instance name", instanceName, name, method)
+		}
+		instance.name = instanceName
+		instance.named = true
+	}
+	return nil
+}
+
+// applyNamingStrategy renames the instances of the modules which are not named explicitly. It returns error if a
+// derived name is invalid.
+func applyNamingStrategy(rms []*reflectedModule, strategy NamingStrategy) error {
+	if strategy == nil {
+		return nil
+	}
+	for _, rm := range rms {
+		for _, instance := range rm.instances {
+			if instance.named {
+				continue
+			}
+			name := strategy(rm.name, instance.methodName)
+			if !isInstanceName(name) {
+				return fmt.Errorf("name %q derived for %s.%s is not a valid instance name",
+					name, rm.name, instance.methodName)
+			}
+			instance.name = name
+		}
+	}
+	return nil
+}
+
+// isInstanceName checks if the name is a valid instance name, which consists of letters, digits, underscores and
+// hyphens. Dots are not allowed because they separate namespaces.
+func isInstanceName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '-' {
+			return false
+		}
+	}
+	return true
+}
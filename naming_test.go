@@ -0,0 +1,146 @@
+package alice
+
+import (
+	"reflect"
+	"testing"
+)
+
+type HTTPClientModule struct {
+	BaseModule
+}
+
+func (m *HTTPClientModule) AliceNames() map[string]string {
+	return map[string]string{
+		"HTTPClient": "http-client",
+	}
+}
+
+func (m *HTTPClientModule) AliceAliases() map[string][]Alias {
+	return map[string][]Alias{
+		"HTTPClient": {{Name: "Client"}},
+	}
+}
+
+func (m *HTTPClientModule) HTTPClient() D1 {
+	return &D1Impl{}
+}
+
+func (m *HTTPClientModule) RetryPolicy() D2 {
+	return &D2Impl{}
+}
+
+type HTTPClientUserModule struct {
+	BaseModule
+	Client      D1 `alice:"http-client"`
+	RetryPolicy D2 `alice:"retry_policy"`
+}
+
+type HTTPServerModule struct {
+	BaseModule
+	*HTTPClientModule
+}
+
+func (m *HTTPServerModule) Address() string {
+	return ":8080"
+}
+
+type undefinedNameModule struct {
+	BaseModule
+}
+
+func (m *undefinedNameModule) AliceNames() map[string]string {
+	return map[string]string{
+		"D1": "d1",
+	}
+}
+
+type invalidNameModule struct {
+	BaseModule
+}
+
+func (m *invalidNameModule) AliceNames() map[string]string {
+	return map[string]string{
+		"D1": "config.d1",
+	}
+}
+
+func (m *invalidNameModule) D1() D1 {
+	return &D1Impl{}
+}
+
+func TestNamingStrategies(t *testing.T) {
+	tests := []struct {
+		strategy NamingStrategy
+		method   string
+		expected string
+	}{
+		{LowerFirst, "HTTPClient", "httpClient"},
+		{LowerFirst, "Client", "client"},
+		{LowerFirst, "URL", "url"},
+		{ModulePrefixed, "HTTPClient", "ClientHTTPClient"},
+		{SnakeCase, "HTTPClient", "http_client"},
+		{SnakeCase, "RetryPolicy", "retry_policy"},
+		{SnakeCase, "D1", "d1"},
+	}
+	for _, test := range tests {
+		if name := test.strategy("ClientModule", test.method); name != test.expected {
+			t.Errorf("bad name of ClientModule.%s: got %s, expected %s", test.method, name, test.expected)
+		}
+	}
+}
+
+func TestWithNamingStrategy(t *testing.T) {
+	user := &HTTPClientUserModule{}
+	c, err := NewContainerWithOptions([]Module{&HTTPClientModule{}, user}, WithNamingStrategy(SnakeCase))
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(user.Client, &D1Impl{}) {
+		t.Errorf("bad field assigned by explicit name: got %v, expected %v", user.Client, &D1Impl{})
+	}
+	if !reflect.DeepEqual(user.RetryPolicy, &D2Impl{}) {
+		t.Errorf("bad field assigned by derived name: got %v, expected %v", user.RetryPolicy, &D2Impl{})
+	}
+	if d1 := c.InstanceByName("Client"); d1 != user.Client {
+		t.Errorf("bad instance after InstanceByName() by alias: got %v, expected %v", d1, user.Client)
+	}
+}
+
+func TestWithNamingStrategy_InvalidName(t *testing.T) {
+	strategy := func(module string, method string) string {
+		return module + "." + method
+	}
+	_, err := NewContainerWithOptions([]Module{&HTTPClientModule{}}, WithNamingStrategy(strategy))
+	if err == nil {
+		t.Error("expect error after NewContainer() on invalid derived name")
+	}
+	t.Log(err.Error())
+}
+
+func TestNames_EmbeddedModule(t *testing.T) {
+	c, err := NewContainer(&HTTPServerModule{HTTPClientModule: &HTTPClientModule{}})
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if d1 := c.InstanceByName("http-client"); !reflect.DeepEqual(d1, &D1Impl{}) {
+		t.Errorf("bad instance after InstanceByName(): got %v, expected %v", d1, &D1Impl{})
+	}
+}
+
+func TestNames_UndefinedProvider(t *testing.T) {
+	_, err := reflectModule(&undefinedNameModule{})
+	if err == nil {
+		t.Error("expect error after reflectModule() on name of undefined provider")
+	}
+	t.Log(err.Error())
+}
+
+func TestNames_InvalidName(t *testing.T) {
+	_, err := reflectModule(&invalidNameModule{})
+	if err == nil {
+		t.Error("expect error after reflectModule() on invalid name")
+	}
+	t.Log(err.Error())
+}
@@ -0,0 +1,59 @@
+package alice
+
+import (
+	"context"
+	"log"
+	"log/slog"
+)
+
+// Option configures how a container is built. Options are passed to NewContainerWithOptions after the modules:
+//
+//	c, err := alice.NewContainerWithOptions(
+//		[]alice.Module{&ConfigModule{}, &ClientModule{}, &BusinessModule{}},
+//		alice.WithOverride("HTTPClient", fakeClient))
+type Option interface {
+	apply(o *options)
+}
+
+// optionFunc is an Option implemented by a function.
+type optionFunc func(o *options)
+
+func (f optionFunc) apply(o *options) {
+	f(o)
+}
+
+// options contains the settings collected from Options.
+type options struct {
+	// ctx is passed to the providers taking a context.Context. It is nil unless set by WithContext.
+	ctx               context.Context
+	instanceOverrides []*namedInstance
+	instances         []*namedInstance
+	moduleOverrides   []Module
+	namingStrategy    NamingStrategy
+	profiles          []string
+	lifecycleHooks    bool
+	listeners         []Listener
+	retryPolicies     []*namedRetryPolicy
+	logf              func(format string, args ...interface{})
+	logger            *slog.Logger
+}
+
+// namedInstance is an instance with a name, set by WithOverride or WithInstance.
+type namedInstance struct {
+	name     string
+	instance interface{}
+}
+
+// defaultOptions returns the settings used when no Option is passed.
+func defaultOptions() *options {
+	return &options{logf: log.Printf}
+}
+
+// applyOptions applies the Options in order on top of the default settings.
+func applyOptions(opts []Option) *options {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt.apply(o)
+	}
+	return o
+}
@@ -0,0 +1,21 @@
+package alice
+
+import (
+	"testing"
+)
+
+func TestApplyOptions(t *testing.T) {
+	fake := &D1Fake{}
+
+	opts := applyOptions([]Option{WithOverride("D1", fake), WithProfiles("prod")})
+
+	if len(opts.instanceOverrides) != 1 || opts.instanceOverrides[0].name != "D1" {
+		t.Errorf("bad instance overrides after applyOptions(): got %v", opts.instanceOverrides)
+	}
+	if len(opts.profiles) != 1 || opts.profiles[0] != "prod" {
+		t.Errorf("bad profiles after applyOptions(): got %v", opts.profiles)
+	}
+	if opts.logf == nil {
+		t.Error("bad logf after applyOptions(): got nil, expected the default")
+	}
+}
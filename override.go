@@ -0,0 +1,107 @@
+package alice
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Override describes an instance which was replaced when building a container.
+type Override struct {
+	// Name is the name of the overridden instance.
+	Name string
+	// Module is the name of the module whose provider was replaced.
+	Module string
+	// By is the name of the overriding module. It is empty if the instance was replaced by WithOverride.
+	By string
+}
+
+// WithOverride replaces the instance with the specified name by the given instance. The provider method of the
+// instance is not called, and dependents receive the given instance instead. The instance must be assignable to the
+// return type of the provider. It is mostly used in tests to replace production instances with fakes.
+func WithOverride(name string, instance interface{}) Option {
+	return optionFunc(func(o *options) {
+		o.instanceOverrides = append(o.instanceOverrides, &namedInstance{
+			name:     name,
+			instance: instance,
+		})
+	})
+}
+
+// WithModuleOverride adds a module whose instances replace the instances with the same names provided by other
+// modules. The replaced providers are not called and are not reported as duplicated. Instances of the overriding
+// module which don't match any existing instance are added as usual.
+func WithModuleOverride(m Module) Option {
+	return optionFunc(func(o *options) {
+		o.moduleOverrides = append(o.moduleOverrides, m)
+	})
+}
+
+// applyModuleOverrides removes the instances of modules which are provided by overriding modules as well.
+func applyModuleOverrides(rms []*reflectedModule, overridingRms []*reflectedModule) []Override {
+	var overrides []Override
+	for _, overridingRm := range overridingRms {
+		for _, instance := range overridingRm.instances {
+			name := overridingRm.qualifiedName(instance.name)
+			for _, rm := range rms {
+				if rm.removeQualifiedInstance(name) {
+					overrides = append(overrides, Override{
+						Name:   name,
+						Module: rm.name,
+						By:     overridingRm.name,
+					})
+				}
+			}
+		}
+	}
+	return overrides
+}
+
+// applyInstanceOverrides makes the providers of overridden instances return the overriding instances. It returns
+// error if no instance matches the name, or the overriding instance is not assignable to the instance type.
+func applyInstanceOverrides(rms []*reflectedModule, instanceOverrides []*namedInstance) ([]Override, error) {
+	var overrides []Override
+	for _, o := range instanceOverrides {
+		rm, instance, err := findInstanceMethod(rms, o.name)
+		if err != nil {
+			return nil, err
+		}
+		if instance == nil {
+			return nil, fmt.Errorf("override %s does not match any instance", o.name)
+		}
+		v := reflect.ValueOf(o.instance)
+		if !v.IsValid() || !v.Type().AssignableTo(instance.tp) {
+			return nil, fmt.Errorf("override %s of type %v is not assignable to %s.%s of type %s",
+				o.name, reflect.TypeOf(o.instance), rm.name, instance.name, instance.tp)
+		}
+
+		value := reflect.New(instance.tp).Elem()
+		value.Set(v)
+		results := []reflect.Value{value}
+		if instance.withError {
+			results = append(results, reflect.Zero(_ErrorType))
+		}
+		instance.method = reflect.MakeFunc(instance.method.Type(), func([]reflect.Value) []reflect.Value {
+			return results
+		})
+		instance.supplied = true
+		overrides = append(overrides, Override{
+			Name:   rm.qualifiedName(instance.name),
+			Module: rm.name,
+		})
+	}
+	return overrides, nil
+}
+
+// findInstanceMethod finds the instance with the specified name and the module providing it. The name is either
+// qualified, or unqualified if it is unambiguous. It returns error if the name is ambiguous or duplicated.
+func findInstanceMethod(rms []*reflectedModule, name string) (*reflectedModule, *instanceMethod, error) {
+	names := newNameIndex()
+	if err := names.add(rms...); err != nil {
+		return nil, nil, err
+	}
+	rm, instance, candidates := names.lookup(name)
+	if len(candidates) > 1 {
+		return nil, nil, fmt.Errorf("override %s is ambiguous, use one of the qualified names %v", name, candidates)
+	}
+	return rm, instance, nil
+}
@@ -0,0 +1,98 @@
+package alice
+
+import (
+	"reflect"
+	"testing"
+)
+
+type D1Fake struct{}
+
+func (d *D1Fake) D1() {}
+
+type M1Override struct {
+	BaseModule
+}
+
+func (m *M1Override) D1() D1 {
+	return &D1Fake{}
+}
+
+func TestPopulate_ModuleOverride(t *testing.T) {
+	var (
+		m1 = &M1{}
+		m2 = &M2{}
+		m3 = &M3{}
+		m4 = &M4{}
+	)
+
+	c := newContainer([]Module{m1, m2, m3, m4}, []Option{WithModuleOverride(&M1Override{})})
+	c.populate()
+
+	if _, ok := m2.D1.(*D1Fake); !ok {
+		t.Errorf("bad m2.D1 after populate() with module override: got %v, expected %v", m2.D1, &D1Fake{})
+	}
+	if _, ok := m4.D1.(*D1Fake); !ok {
+		t.Errorf("bad m4.D1 after populate() with module override: got %v, expected %v", m4.D1, &D1Fake{})
+	}
+	if _, ok := m2.D2.(*D2Impl); !ok {
+		t.Errorf("bad m2.D2 after populate() with module override: got %v, expected %v", m2.D2, &D2Impl{})
+	}
+
+	expectedOverrides := []Override{
+		{Name: "D1", Module: "M1", By: "M1Override"},
+	}
+	if !reflect.DeepEqual(c.Overrides(), expectedOverrides) {
+		t.Errorf("bad overrides after populate(): got %v, expected %v", c.Overrides(), expectedOverrides)
+	}
+}
+
+func TestPopulate_InstanceOverride(t *testing.T) {
+	var (
+		m1 = &M1{}
+		m2 = &M2{}
+		m3 = &M3{}
+		m4 = &M4{}
+	)
+
+	fake := &D1Fake{}
+	c := newContainer([]Module{m1, m2, m3, m4}, []Option{WithOverride("D1", fake)})
+	c.populate()
+
+	if m2.D1 != fake {
+		t.Errorf("bad m2.D1 after populate() with instance override: got %v, expected %v", m2.D1, fake)
+	}
+	if d1 := c.InstanceByName("D1"); d1 != fake {
+		t.Errorf("bad instance from InstanceByName() with instance override: got %v, expected %v", d1, fake)
+	}
+
+	expectedOverrides := []Override{
+		{Name: "D1", Module: "M1"},
+	}
+	if !reflect.DeepEqual(c.Overrides(), expectedOverrides) {
+		t.Errorf("bad overrides after populate(): got %v, expected %v", c.Overrides(), expectedOverrides)
+	}
+}
+
+func TestPopulate_PanicOnOverrideNameNotFound(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected panic after populate() on override name not found")
+		} else {
+			t.Log(r)
+		}
+	}()
+	c := newContainer([]Module{&M1{}}, []Option{WithOverride("D3", &D3Impl{})})
+	c.populate()
+}
+
+func TestPopulate_PanicOnOverrideNotAssignable(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected panic after populate() on override not assignable")
+		} else {
+			t.Log(r)
+		}
+	}()
+	c := newContainer([]Module{&M1{}}, []Option{WithOverride("D1", &D3Impl{})})
+	c.populate()
+}
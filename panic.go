@@ -0,0 +1,81 @@
+package alice
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strings"
+)
+
+// PanicError is returned when a provider method, a lifecycle hook or a decorator panics while the container is built.
+// As on any other build failure, the instances which are built already and implement io.Closer are closed in reverse
+// order before it is returned, except the ones passed by WithInstance or WithOverride. An instance whose lifecycle hook
+// or decorator fails is closed as well.
+type PanicError struct {
+	// Module is the name of the module providing the instance.
+	Module string
+	// Method is the name of the provider method.
+	Method string
+	// Instance is the qualified name of the instance.
+	Instance string
+	// Path is the chain of modules which the module depends on, from the first instantiated to the module itself.
+	Path []string
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the goroutine when it panicked.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("provider %s.%s of instance %s panicked: %v (dependency path: %s)",
+		e.Module, e.Method, e.Instance, e.Value, strings.Join(e.Path, " -> "))
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// newPanicError creates a PanicError of the provider from a recovered value. The path is set by the caller, which
+// knows the graph.
+func newPanicError(rm *reflectedModule, im *instanceMethod, value interface{}) *PanicError {
+	return &PanicError{
+		Module:   rm.name,
+		Method:   im.methodName,
+		Instance: rm.qualifiedName(im.name),
+		Value:    value,
+		Stack:    debug.Stack(),
+	}
+}
+
+// dependencyPath returns the names of the modules leading to the module, following the first dependency in the
+// instantiation order at each step.
+func dependencyPath(g *graph, order []*reflectedModule, rm *reflectedModule) []string {
+	path := []string{rm.name}
+	for current := rm; current != nil; {
+		var next *reflectedModule
+		for _, dep := range order {
+			if dep == current {
+				break
+			}
+			if g.g[dep][current] {
+				next = dep
+				break
+			}
+		}
+		if next != nil {
+			path = append([]string{next.name}, path...)
+		}
+		current = next
+	}
+	return path
+}
+
+// closeInstances closes the instances implementing io.Closer in reverse order of being built, when the build fails.
+// Errors are ignored, since the build has failed already.
+func closeInstances(closers []io.Closer) {
+	for i := len(closers) - 1; i >= 0; i-- {
+		closers[i].Close()
+	}
+}
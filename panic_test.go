@@ -0,0 +1,188 @@
+package alice
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type closingInstance struct {
+	name   string
+	closed *[]string
+	err    error
+}
+
+func (c *closingInstance) Close() error {
+	*c.closed = append(*c.closed, c.name)
+	return c.err
+}
+
+type ClosingModule struct {
+	BaseModule
+	Closed []string
+}
+
+func (m *ClosingModule) First() *closingInstance {
+	return &closingInstance{name: "First", closed: &m.Closed}
+}
+
+func (m *ClosingModule) Second() *closingInstance {
+	return &closingInstance{name: "Second", closed: &m.Closed}
+}
+
+type PanickingModule struct {
+	BaseModule
+	First *closingInstance `alice:"First"`
+	Value interface{}
+}
+
+func (m *PanickingModule) Broken() D1 {
+	panic(m.Value)
+}
+
+func TestNewContainer_Panic(t *testing.T) {
+	closing := &ClosingModule{}
+	_, err := NewContainer(closing, &PanickingModule{Value: "no connection"})
+	if err == nil {
+		t.Fatal("expect error after NewContainer() on panicking provider")
+	}
+	t.Log(err.Error())
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("bad error type after NewContainer(): got %T, expected *PanicError", err)
+	}
+	if panicErr.Module != "PanickingModule" || panicErr.Method != "Broken" || panicErr.Instance != "Broken" ||
+		panicErr.Value != "no connection" {
+		t.Errorf("bad PanicError: %+v", panicErr)
+	}
+	if expected := []string{"ClosingModule", "PanickingModule"}; !reflect.DeepEqual(panicErr.Path, expected) {
+		t.Errorf("bad dependency path: got %v, expected %v", panicErr.Path, expected)
+	}
+	if !strings.Contains(string(panicErr.Stack), "PanickingModule).Broken") {
+		t.Errorf("bad stack: got\n%s\nexpected to contain the provider", panicErr.Stack)
+	}
+	if expected := []string{"Second", "First"}; !reflect.DeepEqual(closing.Closed, expected) {
+		t.Errorf("bad closed instances: got %v, expected %v", closing.Closed, expected)
+	}
+}
+
+type FailingModule struct {
+	BaseModule
+	First *closingInstance `alice:"First"`
+}
+
+func (m *FailingModule) Broken() (D1, error) {
+	return nil, errors.New("no connection")
+}
+
+func TestNewContainer_ErrorClosesInstances(t *testing.T) {
+	closing := &ClosingModule{}
+	var suppliedClosed []string
+	supplied := &closingInstance{name: "Supplied", closed: &suppliedClosed}
+	_, err := NewContainerWithOptions([]Module{closing, &FailingModule{}}, WithInstance("Supplied", supplied))
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) {
+		t.Fatalf("bad error after NewContainer(): got %v, expected *ProviderError", err)
+	}
+
+	if expected := []string{"Second", "First"}; !reflect.DeepEqual(closing.Closed, expected) {
+		t.Errorf("bad closed instances: got %v, expected %v", closing.Closed, expected)
+	}
+	if len(suppliedClosed) != 0 {
+		t.Errorf("bad closed instances: the instance passed by WithInstance is closed")
+	}
+}
+
+func TestNewContainer_OverrideNotClosed(t *testing.T) {
+	var overrideClosed []string
+	override := &closingInstance{name: "Override", closed: &overrideClosed}
+	closing := &ClosingModule{}
+	_, err := NewContainerWithOptions([]Module{closing, &FailingModule{}}, WithOverride("Second", override))
+	if err == nil {
+		t.Fatal("expect error after NewContainer() on failing provider")
+	}
+
+	if expected := []string{"First"}; !reflect.DeepEqual(closing.Closed, expected) {
+		t.Errorf("bad closed instances: got %v, expected %v", closing.Closed, expected)
+	}
+	if len(overrideClosed) != 0 {
+		t.Errorf("bad closed instances: the instance passed by WithOverride is closed")
+	}
+}
+
+type invalidClosingInstance struct {
+	closingInstance
+}
+
+func (i *invalidClosingInstance) Validate() error {
+	return errors.New("empty table name")
+}
+
+type InvalidClosingModule struct {
+	BaseModule
+	Closed []string
+}
+
+func (m *InvalidClosingModule) DAO() *invalidClosingInstance {
+	return &invalidClosingInstance{closingInstance{name: "DAO", closed: &m.Closed}}
+}
+
+func TestNewContainer_HookErrorClosesInstance(t *testing.T) {
+	m := &InvalidClosingModule{}
+	_, err := NewContainerWithOptions([]Module{m}, WithLifecycleHooks())
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) {
+		t.Fatalf("bad error after NewContainer(): got %v, expected *HookError", err)
+	}
+
+	if expected := []string{"DAO"}; !reflect.DeepEqual(m.Closed, expected) {
+		t.Errorf("bad closed instances: got %v, expected %v", m.Closed, expected)
+	}
+}
+
+type PanickingDecoratorModule struct {
+	BaseModule
+}
+
+func (m *PanickingDecoratorModule) AliceDecorators() map[string]string {
+	return map[string]string{
+		"Decorate": "First",
+	}
+}
+
+func (m *PanickingDecoratorModule) Decorate(instance *closingInstance) *closingInstance {
+	panic("no metrics")
+}
+
+func TestNewContainer_DecoratorPanicClosesInstance(t *testing.T) {
+	closing := &ClosingModule{}
+	_, err := NewContainer(closing, &PanickingDecoratorModule{})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("bad error after NewContainer(): got %v, expected *PanicError", err)
+	}
+
+	if expected := []string{"First"}; !reflect.DeepEqual(closing.Closed, expected) {
+		t.Errorf("bad closed instances: got %v, expected %v", closing.Closed, expected)
+	}
+}
+
+func TestNewContainer_PanicError(t *testing.T) {
+	cause := errors.New("no connection")
+	_, err := NewContainer(&ClosingModule{}, &PanickingModule{Value: cause})
+	if !errors.Is(err, cause) {
+		t.Errorf("bad unwrapped error: got %v, expected %v", err, cause)
+	}
+}
+
+func TestCreateContainer_Panic(t *testing.T) {
+	defer func() {
+		r := recover()
+		if _, ok := r.(*PanicError); !ok {
+			t.Errorf("bad recovered value after CreateContainer(): got %T, expected *PanicError", r)
+		}
+	}()
+	CreateContainer(&ClosingModule{}, &PanickingModule{Value: "no connection"})
+}
@@ -0,0 +1,139 @@
+package alice
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const _AliceProfilesMethodName = "AliceProfiles"
+const _AliceProviderProfilesMethodName = "AliceProviderProfiles"
+
+// DefaultProfile is the profile which is active if no profile is set by WithProfiles.
+const DefaultProfile = "default"
+
+// WithProfiles activates the profiles, e.g. "dev" or "prod". Modules and providers declaring profiles are removed
+// before the graph is constructed unless one of their profiles is active, so they are neither instantiated nor
+// reported as duplicated. Modules and providers declaring no profile are always active.
+func WithProfiles(profiles ...string) Option {
+	return optionFunc(func(o *options) {
+		o.profiles = append(o.profiles, profiles...)
+	})
+}
+
+// ProfiledModule is implemented by modules which are active only in some profiles. A profile prefixed by "!" matches
+// when the profile is not active, e.g. "!prod" for every environment other than production.
+//
+//	func (m *InMemoryDAOModule) AliceProfiles() []string {
+//		return []string{"dev", "test"}
+//	}
+//
+// Modules embedded in an inactive module are not reflected through it.
+type ProfiledModule interface {
+	Module
+	// AliceProfiles returns the profiles the module is active in.
+	AliceProfiles() []string
+}
+
+// ProfiledProvidersModule is implemented by modules whose providers are active only in some profiles. Providers not
+// listed are always active as long as the module is.
+//
+//	func (m *ClientModule) AliceProviderProfiles() map[string][]string {
+//		return map[string][]string{
+//			"FakeHTTPClient": {"!prod"},
+//		}
+//	}
+type ProfiledProvidersModule interface {
+	Module
+	// AliceProviderProfiles returns the profiles the providers are active in, keyed by the provider method names.
+	AliceProviderProfiles() map[string][]string
+}
+
+// moduleProfiles returns the profiles of the module if it implements ProfiledModule. It returns error if a profile is
+// empty.
+func moduleProfiles(m Module) ([]string, error) {
+	pm, ok := m.(ProfiledModule)
+	if !ok || !declaresHook(m, _AliceProfilesMethodName) {
+		return nil, nil
+	}
+	profiles := pm.AliceProfiles()
+	if err := checkProfiles(profiles); err != nil {
+		return nil, fmt.Errorf("module %s: %s", reflect.TypeOf(m).Elem().Name(), err.Error())
+	}
+	return profiles, nil
+}
+
+// applyProviderProfiles sets the profiles of the instances if the module implements ProfiledProvidersModule. It
+// returns error if a provider is not defined, or a profile is empty.
+func applyProviderProfiles(m Module, instances []*instanceMethod) error {
+	pm, ok := m.(ProfiledProvidersModule)
+	if !ok || !declaresHook(m, _AliceProviderProfilesMethodName) {
+		return nil
+	}
+
+	name := reflect.TypeOf(m).Elem().Name()
+	for method, profiles := range pm.AliceProviderProfiles() {
+		instance := findInstance(instances, method)
+		if instance == nil {
+			return fmt.Errorf("profiled provider %s.%s is not defined", name, method)
+		}
+		if err := checkProfiles(profiles); err != nil {
+			return fmt.Errorf("provider %s.%s: %s", name, method, err.Error())
+		}
+		instance.profiles = profiles
+	}
+	return nil
+}
+
+// checkProfiles returns error if a profile is empty.
+func checkProfiles(profiles []string) error {
+	for _, profile := range profiles {
+		if strings.TrimPrefix(profile, "!") == "" {
+			return fmt.Errorf("profile %q is empty", profile)
+		}
+	}
+	return nil
+}
+
+// profileSet is the set of active profiles.
+type profileSet map[string]bool
+
+// newProfileSet creates the set of active profiles. DefaultProfile is active if there is no profile.
+func newProfileSet(profiles []string) profileSet {
+	if len(profiles) == 0 {
+		profiles = []string{DefaultProfile}
+	}
+	ps := make(profileSet)
+	for _, profile := range profiles {
+		ps[profile] = true
+	}
+	return ps
+}
+
+// matches checks if any of the profiles is active, or there is no profile at all.
+func (ps profileSet) matches(profiles []string) bool {
+	if len(profiles) == 0 {
+		return true
+	}
+	for _, profile := range profiles {
+		if name := strings.TrimPrefix(profile, "!"); name != profile {
+			if !ps[name] {
+				return true
+			}
+		} else if ps[profile] {
+			return true
+		}
+	}
+	return false
+}
+
+// removeInactiveProviders removes the instances whose profiles are not active.
+func (rm *reflectedModule) removeInactiveProviders(ps profileSet) {
+	var instances []*instanceMethod
+	for _, instance := range rm.instances {
+		if ps.matches(instance.profiles) {
+			instances = append(instances, instance)
+		}
+	}
+	rm.instances = instances
+}
@@ -0,0 +1,160 @@
+package alice
+
+import (
+	"reflect"
+	"testing"
+)
+
+type InMemoryD1Module struct {
+	BaseModule
+}
+
+func (m *InMemoryD1Module) AliceProfiles() []string {
+	return []string{"!prod"}
+}
+
+func (m *InMemoryD1Module) D1() D1 {
+	return &D1Impl{}
+}
+
+type ProdD1Module struct {
+	BaseModule
+}
+
+func (m *ProdD1Module) AliceProfiles() []string {
+	return []string{"prod"}
+}
+
+func (m *ProdD1Module) D1() D1 {
+	return &decoratedD1{base: &D1Impl{}}
+}
+
+type FakeD2Module struct {
+	BaseModule
+}
+
+func (m *FakeD2Module) AliceProviderProfiles() map[string][]string {
+	return map[string][]string{
+		"FakeD2": {"dev", "test"},
+		"D2":     {"staging", "prod"},
+	}
+}
+
+func (m *FakeD2Module) FakeD2() D2 {
+	return &D2Impl{}
+}
+
+func (m *FakeD2Module) D2() D2 {
+	return &D2Impl{}
+}
+
+type ProfiledDependantModule struct {
+	BaseModule
+	D1 D1 `alice:"D1"`
+	D2 D2 `alice:""`
+}
+
+type ProfiledServerModule struct {
+	BaseModule
+	*ProdD1Module
+	*FakeD2Module
+}
+
+func (m *ProfiledServerModule) Address() string {
+	return ":8080"
+}
+
+type undefinedProfileModule struct {
+	BaseModule
+}
+
+func (m *undefinedProfileModule) AliceProviderProfiles() map[string][]string {
+	return map[string][]string{
+		"D1": {"dev"},
+	}
+}
+
+type emptyProfileModule struct {
+	BaseModule
+}
+
+func (m *emptyProfileModule) AliceProfiles() []string {
+	return []string{"!"}
+}
+
+func TestWithProfiles(t *testing.T) {
+	tests := []struct {
+		profiles   []string
+		expectedD1 D1
+		expectedD2 string
+	}{
+		{[]string{"prod"}, &decoratedD1{base: &D1Impl{}}, "D2"},
+		{[]string{"dev", "eu"}, &D1Impl{}, "FakeD2"},
+	}
+	for _, test := range tests {
+		dependant := &ProfiledDependantModule{}
+		c, err := NewContainerWithOptions(
+			[]Module{&InMemoryD1Module{}, &ProdD1Module{}, &FakeD2Module{}, dependant}, WithProfiles(test.profiles...))
+		if err != nil {
+			t.Fatalf("unexpected error after NewContainer() with profiles %v: %s", test.profiles, err.Error())
+		}
+
+		if !reflect.DeepEqual(dependant.D1, test.expectedD1) {
+			t.Errorf("bad D1 with profiles %v: got %v, expected %v", test.profiles, dependant.D1, test.expectedD1)
+		}
+		if d2 := c.InstanceByName(test.expectedD2); d2 != dependant.D2 {
+			t.Errorf("bad D2 with profiles %v: got %v, expected %s", test.profiles, dependant.D2, test.expectedD2)
+		}
+	}
+}
+
+func TestWithProfiles_Default(t *testing.T) {
+	dependant := &ProfiledDependantModule{}
+	_, err := NewContainer(&InMemoryD1Module{}, &ProdD1Module{}, &FakeD2Module{}, dependant)
+	if err == nil {
+		t.Error("expect error after NewContainer() without profiles of D2")
+	}
+	t.Log(err.Error())
+
+	c, err := NewContainer(&InMemoryD1Module{}, &ProdD1Module{})
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+	if d1 := c.InstanceByName("D1"); !reflect.DeepEqual(d1, &D1Impl{}) {
+		t.Errorf("bad D1 in default profile: got %v, expected %v", d1, &D1Impl{})
+	}
+}
+
+func TestProfiles_EmbeddedModules(t *testing.T) {
+	m := &ProfiledServerModule{ProdD1Module: &ProdD1Module{}, FakeD2Module: &FakeD2Module{}}
+	c, err := NewContainerWithOptions([]Module{m}, WithProfiles("dev"))
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if address := c.InstanceByName("Address"); address != ":8080" {
+		t.Errorf("bad instance Address of module not in prod profile: got %v, expected :8080", address)
+	}
+	if d2 := c.InstanceByName("FakeD2"); !reflect.DeepEqual(d2, &D2Impl{}) {
+		t.Errorf("bad instance FakeD2 in dev profile: got %v, expected %v", d2, &D2Impl{})
+	}
+	if _, ok := c.(*container).instanceByName["D1"]; ok {
+		t.Error("bad instance D1 of embedded module in prod profile: expected none")
+	}
+}
+
+func TestProfiles_UndefinedProvider(t *testing.T) {
+	_, err := reflectModule(&undefinedProfileModule{})
+	if err == nil {
+		t.Error("expect error after reflectModule() on profiles of undefined provider")
+	}
+	t.Log(err.Error())
+}
+
+func TestProfiles_Empty(t *testing.T) {
+	_, err := reflectModule(&emptyProfileModule{})
+	if err == nil {
+		t.Error("expect error after reflectModule() on empty profile")
+	}
+	t.Log(err.Error())
+}
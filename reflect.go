@@ -3,36 +3,75 @@ package alice
 import (
 	"fmt"
 	"reflect"
+	"runtime"
 )
 
 const _Tag = "alice"
 const _IsModuleMethodName = "IsModule"
+const _AliceIgnoreMethodName = "AliceIgnore"
+
+var _ModuleType = reflect.TypeOf((*Module)(nil)).Elem()
+var _BaseModuleType = reflect.TypeOf(BaseModule{})
+
+// _AutogeneratedFile is the file name of functions generated by the compiler, such as wrappers of promoted methods.
+const _AutogeneratedFile = "<autogenerated>"
 
 // reflectedModule contains the instance and dependency information of a Module. The information is extracted
 // using reflection.
 type reflectedModule struct {
-	m    Module
-	name string
+	m         Module
+	name      string
+	namespace string
+	// profiles are the profiles the module is active in. It is active in all profiles if empty.
+	profiles []string
 
 	instances    []*instanceMethod
 	namedDepends []*namedField
 	typedDepends []*typedField
+
+	// embedded are the modules embedded in the module.
+	embedded []*reflectedModule
+	// bases are the embedded modules whose providers are overridden by the module. The module is instantiated after
+	// them, so the overridden providers could be called by the overriding ones.
+	bases []*reflectedModule
+	// decorators are the methods of the module decorating instances, sorted by name.
+	decorators []*decoratorMethod
 }
 
 type instanceMethod struct {
-	name   string
-	tp     reflect.Type
-	method reflect.Value
+	name string
+	// methodName is the name of the provider method. It is the same as name unless the instance is renamed.
+	methodName string
+	// named is true if the instance is named explicitly by AliceNames, so the naming strategy doesn't apply.
+	named      bool
+	tp         reflect.Type
+	method     reflect.Value
+	aliases    []Alias
+	profiles   []string
+	conditions []Condition
+	fallback   bool
+	// decorators are applied to the instance in order before it is published.
+	decorators []*decoratorMethod
+	// withContext is true if the provider method takes a context.Context.
+	withContext bool
+	// withError is true if the provider method returns (T, error).
+	withError   bool
+	retryPolicy *RetryPolicy
+	// supplied is true if the instance is passed by the caller through WithInstance or WithOverride, so it is not closed
+	// by the container.
+	supplied bool
 }
 
 type namedField struct {
-	name  string
-	field reflect.Value
+	name      string
+	fieldName string
+	field     reflect.Value
 }
 
 type typedField struct {
-	tp    reflect.Type
-	field reflect.Value
+	tp        reflect.Type
+	fieldName string
+	field     reflect.Value
 }
 
 // reflectModule creates a reflectedModule from a Module. It returns error if the Module is not properly defined.
@@ -42,25 +81,69 @@ func reflectModule(m Module) (*reflectedModule, error) {
 		return nil, fmt.Errorf("module %s is not a pointer of struct", v.String())
 	}
 
+	ignored, err := ignoredMethods(m)
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := moduleNamespace(m)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := moduleProfiles(m)
+	if err != nil {
+		return nil, err
+	}
+
+	decorators, err := moduleDecorators(m)
+	if err != nil {
+		return nil, err
+	}
+
 	// get instances
 	ptrT := v.Type()
 	var instances []*instanceMethod
 	for i := 0; i < ptrT.NumMethod(); i++ {
 		method := ptrT.Method(i)
-		if method.Name == _IsModuleMethodName {
+		if isHookMethod(method.Name) || ignored[method.Name] || isDecorator(decorators, method.Name) ||
+			isPromoted(ptrT.Elem(), method) {
 			continue
 		}
-		if method.Type.NumIn() != 1 || method.Type.NumOut() != 1 { // receiver is the first parameter
-			return nil, fmt.Errorf("method %s.%s doesn't have 0 parameter and 1 return value",
-				v.Elem().Type().Name(), method.Name)
+		// receiver is the first parameter
+		withContext := takesContext(method.Type)
+		withError := returnsError(method.Type)
+		if (method.Type.NumIn() != 1 && !withContext) || (method.Type.NumOut() != 1 && !withError) {
+			return nil, fmt.Errorf("method %s.%s doesn't have 0 parameter or a context.Context parameter, "+
+				"and 1 return value or (T, error)", v.Elem().Type().Name(), method.Name)
 		}
 		instances = append(instances, &instanceMethod{
-			name:   method.Name,
-			tp:     method.Type.Out(0),
-			method: v.MethodByName(method.Name),
+			name:        method.Name,
+			methodName:  method.Name,
+			tp:          method.Type.Out(0),
+			method:      v.MethodByName(method.Name),
+			withContext: withContext,
+			withError:   withError,
 		})
 	}
 
+	if err := applyNames(m, instances); err != nil {
+		return nil, err
+	}
+	if err := applyAliases(m, instances); err != nil {
+		return nil, err
+	}
+	if err := applyProviderProfiles(m, instances); err != nil {
+		return nil, err
+	}
+	if err := applyProviderConditions(m, instances); err != nil {
+		return nil, err
+	}
+	if err := markFallbacks(m, instances); err != nil {
+		return nil, err
+	}
+	if err := applyRetryPolicies(m, instances); err != nil {
+		return nil, err
+	}
+
 	// get dependencies
 	t := v.Elem().Type()
 	var namedDepends []*namedField
@@ -74,13 +157,15 @@ func reflectModule(m Module) (*reflectedModule, error) {
 		if dependName, exists := field.Tag.Lookup(_Tag); exists {
 			if dependName != "" {
 				namedDepends = append(namedDepends, &namedField{
-					name:  dependName,
-					field: v.Elem().FieldByName(field.Name),
+					name:      dependName,
+					fieldName: field.Name,
+					field:     v.Elem().FieldByName(field.Name),
 				})
 			} else {
 				typedDepends = append(typedDepends, &typedField{
-					tp:    field.Type,
-					field: v.Elem().FieldByName(field.Name),
+					tp:        field.Type,
+					fieldName: field.Name,
+					field:     v.Elem().FieldByName(field.Name),
 				})
 			}
 		}
@@ -89,8 +174,225 @@ func reflectModule(m Module) (*reflectedModule, error) {
 	return &reflectedModule{
 		m:            m,
 		name:         t.Name(),
+		namespace:    namespace,
+		profiles:     profiles,
 		instances:    instances,
 		namedDepends: namedDepends,
 		typedDepends: typedDepends,
+		decorators:   decorators,
 	}, nil
 }
+
+// embeddedModule is a module embedded in a module struct.
+type embeddedModule struct {
+	module Module
+	// field is the qualified name of the embedding field, e.g. "ServerModule.LoggingModule".
+	field   string
+	pointer bool
+}
+
+// embeddedModules returns the modules embedded in a module struct, other than BaseModule. A module embedded by value is
+// addressed inside the struct, so its fields are assigned in place. It returns error if an embedded module is
+// unexported or a nil pointer.
+func embeddedModules(m Module) ([]*embeddedModule, error) {
+	v := reflect.ValueOf(m).Elem()
+	t := v.Type()
+	var modules []*embeddedModule
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.Anonymous || field.Type == _BaseModuleType || field.Type == reflect.PtrTo(_BaseModuleType) {
+			continue
+		}
+		fv := v.Field(i)
+		pointer := fv.Kind() == reflect.Ptr
+		if !pointer {
+			fv = fv.Addr()
+		}
+		if !fv.Type().Implements(_ModuleType) {
+			continue
+		}
+		if field.PkgPath != "" {
+			return nil, fmt.Errorf("embedded module %s.%s is not exported", t.Name(), field.Name)
+		}
+		if fv.IsNil() {
+			return nil, fmt.Errorf("embedded module %s.%s is nil", t.Name(), field.Name)
+		}
+		modules = append(modules, &embeddedModule{
+			module:  fv.Interface().(Module),
+			field:   t.Name() + "." + field.Name,
+			pointer: pointer,
+		})
+	}
+	return modules, nil
+}
+
+// isHookMethod checks if the method is defined by alice for modules to implement, rather than a provider.
+func isHookMethod(name string) bool {
+	switch name {
+	case _IsModuleMethodName, _AliceIgnoreMethodName, _AliceNamespaceMethodName, _AliceAliasesMethodName,
+		_AliceNamesMethodName, _AliceProfilesMethodName, _AliceProviderProfilesMethodName, _AliceConditionsMethodName,
+		_AliceFallbacksMethodName, _AliceDecoratorsMethodName, _AliceRetryPoliciesMethodName:
+		return true
+	}
+	return false
+}
+
+// ignoredMethods returns the methods ignored by the module if it implements IgnoringModule. It returns error if an
+// ignored method is not defined.
+func ignoredMethods(m Module) (map[string]bool, error) {
+	ignored := make(map[string]bool)
+	im, ok := m.(IgnoringModule)
+	if !ok {
+		return ignored, nil
+	}
+
+	t := reflect.TypeOf(m)
+	for _, name := range im.AliceIgnore() {
+		if _, ok := t.MethodByName(name); !ok {
+			return nil, fmt.Errorf("ignored method %s.%s is not defined", t.Elem().Name(), name)
+		}
+		ignored[name] = true
+	}
+	return ignored, nil
+}
+
+// isPromoted checks if a method of the pointer type is promoted from an embedded field, rather than declared on the
+// struct type t. The method is promoted if a type embedded in t has a method with the same name. A method declared on t
+// shadowing it isn't told apart by its method set, so a method of an embedded module, which t may override, is only
+// promoted if its function is a wrapper generated by the compiler.
+func isPromoted(t reflect.Type, method reflect.Method) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.Anonymous || !hasMethod(field.Type, method.Name) {
+			continue
+		}
+		if !isModuleType(field.Type) || isWrapper(t, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// declaresHook checks if the module declares the hook method itself. A hook promoted from an embedded module belongs to
+// that module, which is reflected on its own.
+func declaresHook(m Module, name string) bool {
+	t := reflect.TypeOf(m)
+	method, ok := t.MethodByName(name)
+	return ok && !isPromoted(t.Elem(), method)
+}
+
+// hasMethod checks if the type, or its pointer type, has a method with the name.
+func hasMethod(t reflect.Type, name string) bool {
+	if t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface {
+		t = reflect.PtrTo(t)
+	}
+	_, ok := t.MethodByName(name)
+	return ok
+}
+
+// isModuleType checks if the type, or its pointer type, implements Module.
+func isModuleType(t reflect.Type) bool {
+	if t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface {
+		t = reflect.PtrTo(t)
+	}
+	return t.Implements(_ModuleType)
+}
+
+// isWrapper checks if a method of the pointer type is a wrapper generated by the compiler. Wrappers are generated for
+// the pointer type of value receiver methods as well, so the value type t is checked too.
+func isWrapper(t reflect.Type, method reflect.Method) bool {
+	if !isAutogenerated(method.Func) {
+		return false
+	}
+	if valueMethod, ok := t.MethodByName(method.Name); ok && !isAutogenerated(valueMethod.Func) {
+		return false
+	}
+	return true
+}
+
+// isAutogenerated checks if the function is generated by the compiler.
+func isAutogenerated(f reflect.Value) bool {
+	pc := f.Pointer()
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return false
+	}
+	file, _ := fn.FileLine(pc)
+	return file == _AutogeneratedFile
+}
+
+// findInstance finds the instance provided by the method with the name, or nil if not found.
+func findInstance(instances []*instanceMethod, methodName string) *instanceMethod {
+	for _, instance := range instances {
+		if instance.methodName == methodName {
+			return instance
+		}
+	}
+	return nil
+}
+
+// removeInstance removes the instance provided by the method with the specified name from the module. It returns false
+// if the module doesn't provide such an instance.
+func (rm *reflectedModule) removeInstance(methodName string) bool {
+	for i, instance := range rm.instances {
+		if instance.methodName == methodName {
+			rm.instances = append(rm.instances[:i:i], rm.instances[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// overrideEmbeddedProviders removes the providers of the modules embedded in the module, directly or indirectly, which
+// have the same method names as the providers of the module.
+func (rm *reflectedModule) overrideEmbeddedProviders() {
+	for _, instance := range rm.instances {
+		rm.overrideEmbeddedProvider(rm.embedded, instance.methodName, map[*reflectedModule]bool{rm: true})
+	}
+}
+
+// overrideEmbeddedProvider removes the provider with the method name from the embedded modules. A provider of a deeper
+// module is overridden only if it is promoted, i.e. not overridden by the modules in between already. visited avoids
+// infinite recursion when modules embed each other by pointers.
+func (rm *reflectedModule) overrideEmbeddedProvider(
+	embedded []*reflectedModule, name string, visited map[*reflectedModule]bool) {
+	for _, erm := range embedded {
+		if visited[erm] {
+			continue
+		}
+		visited[erm] = true
+		if erm.removeInstance(name) {
+			rm.addBase(erm)
+		} else {
+			rm.overrideEmbeddedProvider(erm.embedded, name, visited)
+		}
+	}
+}
+
+// addBase adds a base module if it is not added yet.
+func (rm *reflectedModule) addBase(base *reflectedModule) {
+	for _, b := range rm.bases {
+		if b == base {
+			return
+		}
+	}
+	rm.bases = append(rm.bases, base)
+}
+
+// instanceOfType returns the instance of the exact type, or the first instance assignable to the type. Fallback
+// instances are skipped. It returns nil if no such instance is found.
+func (rm *reflectedModule) instanceOfType(t reflect.Type) *instanceMethod {
+	var assignable *instanceMethod
+	for _, instance := range rm.instances {
+		if instance.fallback {
+			continue
+		}
+		if instance.tp == t {
+			return instance
+		}
+		if assignable == nil && instance.tp.AssignableTo(t) {
+			assignable = instance
+		}
+	}
+	return assignable
+}
@@ -44,8 +44,52 @@ type invalidMethodModule2 struct {
 	BaseModule
 }
 
-func (m *invalidMethodModule2) Dep2() (D2, error) {
-	return &D2Impl{}, nil
+func (m *invalidMethodModule2) Dep2() (D2, D1) {
+	return &D2Impl{}, &D1Impl{}
+}
+
+type reflectTestHelper struct{}
+
+func (h *reflectTestHelper) Dep2() D2 {
+	return &D2Impl{}
+}
+
+func (h reflectTestHelper) Format(str string) string {
+	return str
+}
+
+type ignoringModule struct {
+	BaseModule
+	reflectTestHelper
+}
+
+func (m *ignoringModule) AliceIgnore() []string {
+	return []string{"Join"}
+}
+
+func (m ignoringModule) Dep1() D1 {
+	return &D1Impl{}
+}
+
+func (m *ignoringModule) Join(a, b string) string {
+	return a + b
+}
+
+type pointerHelperModule struct {
+	BaseModule
+	*reflectTestHelper
+}
+
+func (m *pointerHelperModule) Dep1() D1 {
+	return &D1Impl{}
+}
+
+type undefinedIgnoreModule struct {
+	BaseModule
+}
+
+func (m *undefinedIgnoreModule) AliceIgnore() []string {
+	return []string{"Join"}
 }
 
 func TestReflectModule(t *testing.T) {
@@ -66,14 +110,16 @@ func TestReflectModule(t *testing.T) {
 
 	expectedInstances := []*instanceMethod{
 		{
-			name:   "Dep1",
-			tp:     reflect.TypeOf((*D1)(nil)).Elem(),
-			method: reflect.ValueOf(m).MethodByName("Dep1"),
+			name:       "Dep1",
+			methodName: "Dep1",
+			tp:         reflect.TypeOf((*D1)(nil)).Elem(),
+			method:     reflect.ValueOf(m).MethodByName("Dep1"),
 		},
 		{
-			name:   "Dep2",
-			tp:     reflect.TypeOf((*D2)(nil)).Elem(),
-			method: reflect.ValueOf(m).MethodByName("Dep2"),
+			name:       "Dep2",
+			methodName: "Dep2",
+			tp:         reflect.TypeOf((*D2)(nil)).Elem(),
+			method:     reflect.ValueOf(m).MethodByName("Dep2"),
 		},
 	}
 	if !reflect.DeepEqual(rmodule.instances, expectedInstances) {
@@ -83,8 +129,9 @@ func TestReflectModule(t *testing.T) {
 
 	expectedNamedDepends := []*namedField{
 		{
-			name:  "Dep2",
-			field: reflect.ValueOf(m).Elem().FieldByName("dep2"),
+			name:      "Dep2",
+			fieldName: "dep2",
+			field:     reflect.ValueOf(m).Elem().FieldByName("dep2"),
 		},
 	}
 	if !reflect.DeepEqual(rmodule.namedDepends, expectedNamedDepends) {
@@ -94,8 +141,9 @@ func TestReflectModule(t *testing.T) {
 
 	expectedTypedDpends := []*typedField{
 		{
-			tp:    reflect.TypeOf((*D1)(nil)).Elem(),
-			field: reflect.ValueOf(m).Elem().FieldByName("dep1"),
+			tp:        reflect.TypeOf((*D1)(nil)).Elem(),
+			fieldName: "dep1",
+			field:     reflect.ValueOf(m).Elem().FieldByName("dep1"),
 		},
 	}
 	if !reflect.DeepEqual(rmodule.typedDepends, expectedTypedDpends) {
@@ -133,7 +181,52 @@ func TestReflectModule_InvalidMethod(t *testing.T) {
 	m2 := &invalidMethodModule2{}
 	_, err = reflectModule(m2)
 	if err == nil {
-		t.Error("expect error after reflectModule() on module with 2 return values method")
+		t.Error("expect error after reflectModule() on module with 2 return values method, the second not error")
+	}
+	t.Log(err.Error())
+}
+
+func TestReflectModule_Ignore(t *testing.T) {
+	m := &ignoringModule{}
+
+	rmodule, err := reflectModule(m)
+
+	if err != nil {
+		t.Errorf("unexpected error after reflectModule(): %s", err.Error())
+	}
+	expectedInstances := []*instanceMethod{
+		{
+			name:       "Dep1",
+			methodName: "Dep1",
+			tp:         reflect.TypeOf((*D1)(nil)).Elem(),
+			method:     reflect.ValueOf(m).MethodByName("Dep1"),
+		},
+	}
+	if !reflect.DeepEqual(rmodule.instances, expectedInstances) {
+		t.Errorf("bad instances in reflectedModule: got %v, expected %v",
+			rmodule.instances, expectedInstances)
+	}
+}
+
+func TestReflectModule_PointerHelper(t *testing.T) {
+	m := &pointerHelperModule{reflectTestHelper: &reflectTestHelper{}}
+
+	rmodule, err := reflectModule(m)
+
+	if err != nil {
+		t.Errorf("unexpected error after reflectModule(): %s", err.Error())
+	}
+	if len(rmodule.instances) != 1 || rmodule.instances[0].name != "Dep1" {
+		t.Errorf("bad instances in reflectedModule: got %v, expected only Dep1", rmodule.instances)
+	}
+}
+
+func TestReflectModule_UndefinedIgnore(t *testing.T) {
+	m := &undefinedIgnoreModule{}
+
+	_, err := reflectModule(m)
+	if err == nil {
+		t.Error("expect error after reflectModule() on module ignoring undefined method")
 	}
 	t.Log(err.Error())
 }
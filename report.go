@@ -0,0 +1,130 @@
+package alice
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// BuildReport reports the wall time spent building a container. Durations are serialized to JSON in nanoseconds:
+//
+//	{
+//	  "providers": [
+//	    {"module": "ConfigModule", "method": "Retries", "instance": "Retries", "duration": 300, "attempts": 1},
+//	    {"module": "ClientModule", "method": "HTTPClient", "instance": "HTTPClient", "duration": 1200, "attempts": 1}
+//	  ],
+//	  "modules": [{"module": "ConfigModule", "duration": 300}, {"module": "ClientModule", "duration": 1500}],
+//	  "criticalModules": ["ConfigModule", "ClientModule"],
+//	  "criticalPath": ["ConfigModule.Retries", "ClientModule.HTTPClient"],
+//	  "criticalPathDuration": 1800,
+//	  "total": 2100
+//	}
+//
+// Dependencies are between modules, and the providers of a module are called one after another once its fields are
+// assigned, so the critical path is the chain of dependent modules taking the longest time in total, and the
+// providers of those modules in the order they are called. It bounds the startup time even if independent modules
+// were instantiated concurrently.
+type BuildReport struct {
+	// Providers are the provider calls in the order they are made.
+	Providers []*ProviderTiming `json:"providers"`
+	// Modules are the modules in the instantiation order. The duration of a module includes assigning its fields and
+	// calling its providers.
+	Modules []*ModuleTiming `json:"modules"`
+	// CriticalModules is the names of the modules on the critical path, from the first instantiated to the last.
+	CriticalModules []string `json:"criticalModules"`
+	// CriticalPath is the providers of the modules on the critical path in the order they are called, each as
+	// Module.Method. A module without providers is listed by its name.
+	CriticalPath         []string      `json:"criticalPath"`
+	CriticalPathDuration time.Duration `json:"criticalPathDuration"`
+	// Total is the wall time of the whole build, including reflecting the modules and computing the graph.
+	Total time.Duration `json:"total"`
+}
+
+// ProviderTiming is the wall time spent in a provider method, including its lifecycle hooks and decorators.
+type ProviderTiming struct {
+	Module string `json:"module"`
+	Method string `json:"method"`
+	// Instance is the qualified name of the instance.
+	Instance string `json:"instance"`
+	// Duration includes all the attempts and the waits between them if the provider is retried.
+	Duration time.Duration `json:"duration"`
+	Attempts int           `json:"attempts"`
+}
+
+// ModuleTiming is the wall time spent instantiating a module.
+type ModuleTiming struct {
+	Module   string        `json:"module"`
+	Duration time.Duration `json:"duration"`
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *BuildReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteTable writes the report as aligned text tables of providers and modules, followed by the critical path and the
+// total.
+func (r *BuildReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODULE\tMETHOD\tINSTANCE\tDURATION\tATTEMPTS")
+	for _, p := range r.Providers {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", p.Module, p.Method, p.Instance, p.Duration, p.Attempts)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "MODULE\tDURATION")
+	for _, m := range r.Modules {
+		fmt.Fprintf(tw, "%s\t%s\n", m.Module, m.Duration)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "critical path:\t%s (%s)\n", strings.Join(r.CriticalPath, " -> "), r.CriticalPathDuration)
+	fmt.Fprintf(tw, "total:\t%s\n", r.Total)
+	return tw.Flush()
+}
+
+// computeCriticalPath finds the chain of dependent modules with the longest total duration, and lists their providers.
+// The modules are in the instantiation order, so the dependencies of a module are always computed before it. Ties are
+// broken by the order.
+func (r *BuildReport) computeCriticalPath(g *graph, order []*reflectedModule,
+	durations map[*reflectedModule]time.Duration) {
+	finish := make(map[*reflectedModule]time.Duration)
+	prev := make(map[*reflectedModule]*reflectedModule)
+	var last *reflectedModule
+	for i, rm := range order {
+		for _, dep := range order[:i] {
+			if g.g[dep][rm] && (prev[rm] == nil || finish[dep] > finish[prev[rm]]) {
+				prev[rm] = dep
+			}
+		}
+		finish[rm] = durations[rm]
+		if prev[rm] != nil {
+			finish[rm] += finish[prev[rm]]
+		}
+		if last == nil || finish[rm] > finish[last] {
+			last = rm
+		}
+	}
+
+	var path []*reflectedModule
+	for rm := last; rm != nil; rm = prev[rm] {
+		path = append([]*reflectedModule{rm}, path...)
+	}
+	r.CriticalModules = []string{}
+	r.CriticalPath = []string{}
+	for _, rm := range path {
+		r.CriticalModules = append(r.CriticalModules, rm.name)
+		if len(rm.instances) == 0 {
+			r.CriticalPath = append(r.CriticalPath, rm.name)
+		}
+		for _, im := range rm.instances {
+			r.CriticalPath = append(r.CriticalPath, rm.name+"."+im.methodName)
+		}
+	}
+	if last != nil {
+		r.CriticalPathDuration = finish[last]
+	}
+}
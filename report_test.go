@@ -0,0 +1,112 @@
+package alice
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildReport(t *testing.T) {
+	c, err := NewContainer(&M1{}, &M2{}, &M3{}, &M4{})
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	r := c.BuildReport()
+	var providers []string
+	for _, p := range r.Providers {
+		providers = append(providers, p.Module+"."+p.Method)
+	}
+	expectedProviders := []string{"M1.D1", "M1.D2", "M4.D3", "M4.D4", "M2.D5", "M3.DM3"}
+	if !reflect.DeepEqual(providers, expectedProviders) {
+		t.Errorf("bad providers: got %v, expected %v", providers, expectedProviders)
+	}
+	var modules []string
+	for _, m := range r.Modules {
+		modules = append(modules, m.Module)
+	}
+	if expected := []string{"M1", "M4", "M2", "M3"}; !reflect.DeepEqual(modules, expected) {
+		t.Errorf("bad modules: got %v, expected %v", modules, expected)
+	}
+	if r.Total < r.CriticalPathDuration {
+		t.Errorf("bad total: got %s, expected at least the critical path %s", r.Total, r.CriticalPathDuration)
+	}
+}
+
+func TestBuildReport_CriticalPath(t *testing.T) {
+	p, err := plan([]Module{&M1{}, &M2{}, &M3{}, &M4{}, &M5{}}, defaultOptions())
+	if err != nil {
+		t.Fatalf("unexpected error after plan(): %s", err.Error())
+	}
+	durations := make(map[*reflectedModule]time.Duration)
+	for _, rm := range p.order {
+		durations[rm] = map[string]time.Duration{
+			"M1": 1 * time.Millisecond,
+			"M2": 2 * time.Millisecond,
+			"M3": 3 * time.Millisecond,
+			"M4": 4 * time.Millisecond,
+			"M5": 9 * time.Millisecond,
+		}[rm.name]
+	}
+
+	r := &BuildReport{}
+	r.computeCriticalPath(p.graph, p.order, durations)
+	if expected := []string{"M1", "M4", "M2", "M3"}; !reflect.DeepEqual(r.CriticalModules, expected) {
+		t.Errorf("bad critical modules: got %v, expected %v", r.CriticalModules, expected)
+	}
+	expected := []string{"M1.D1", "M1.D2", "M4.D3", "M4.D4", "M2.D5", "M3.DM3"}
+	if !reflect.DeepEqual(r.CriticalPath, expected) {
+		t.Errorf("bad critical path: got %v, expected %v", r.CriticalPath, expected)
+	}
+	if r.CriticalPathDuration != 10*time.Millisecond {
+		t.Errorf("bad critical path duration: got %s, expected 10ms", r.CriticalPathDuration)
+	}
+}
+
+func TestBuildReport_Write(t *testing.T) {
+	r := &BuildReport{
+		Providers: []*ProviderTiming{
+			{Module: "M1", Method: "D1", Instance: "D1", Duration: time.Millisecond, Attempts: 2},
+		},
+		Modules:              []*ModuleTiming{{Module: "M1", Duration: 2 * time.Millisecond}},
+		CriticalModules:      []string{"M1"},
+		CriticalPath:         []string{"M1.D1"},
+		CriticalPathDuration: 2 * time.Millisecond,
+		Total:                3 * time.Millisecond,
+	}
+
+	var table bytes.Buffer
+	if err := r.WriteTable(&table); err != nil {
+		t.Fatalf("unexpected error after WriteTable(): %s", err.Error())
+	}
+	expected := `MODULE  METHOD  INSTANCE  DURATION  ATTEMPTS
+M1      D1      D1        1ms       2
+
+MODULE  DURATION
+M1      2ms
+
+critical path:  M1.D1 (2ms)
+total:          3ms
+`
+	if table.String() != expected {
+		t.Errorf("bad table: got\n%s\nexpected\n%s", table.String(), expected)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatalf("unexpected error after WriteJSON(): %s", err.Error())
+	}
+	if !strings.Contains(buf.String(), `"criticalPath": [`) {
+		t.Errorf("bad JSON: %s", buf.String())
+	}
+	var decoded BuildReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("unexpected error after json.Unmarshal(): %s", err.Error())
+	}
+	if !reflect.DeepEqual(&decoded, r) {
+		t.Errorf("bad decoded report: got %+v, expected %+v", decoded, *r)
+	}
+}
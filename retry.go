@@ -0,0 +1,180 @@
+package alice
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const _AliceRetryPoliciesMethodName = "AliceRetryPolicies"
+
+var _ErrorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// RetryPolicy retries a provider returning (T, error) when it fails, e.g. a database which is not reachable yet while
+// a deployment is rolling out. Waits between attempts grow exponentially:
+//
+//	alice.RetryPolicy{
+//		Attempts:   5,
+//		Backoff:    100 * time.Millisecond,
+//		MaxBackoff: 2 * time.Second,
+//		Retryable:  func(err error) bool { return errors.Is(err, syscall.ECONNREFUSED) },
+//	}
+type RetryPolicy struct {
+	// Attempts is the maximum number of calls, including the first one. The provider is called once if it is less than
+	// 2.
+	Attempts int
+	// Backoff is the wait before the second attempt. It is multiplied by Multiplier before each further attempt.
+	Backoff time.Duration
+	// Multiplier is the growth of the wait. It defaults to 2 if it is less than 1.
+	Multiplier float64
+	// MaxBackoff caps the wait between attempts if it is positive.
+	MaxBackoff time.Duration
+	// Retryable tells whether an error returned by the provider is transient. All errors are retried if it is nil.
+	Retryable func(err error) bool
+}
+
+// shouldRetry checks if the provider is called again after the attempt fails with the error.
+func (p *RetryPolicy) shouldRetry(err error, attempt int) bool {
+	if p == nil || attempt >= p.Attempts {
+		return false
+	}
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+// backoff returns the wait after the attempt fails.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	wait := p.Backoff
+	for i := 1; ; i++ {
+		if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+			return p.MaxBackoff
+		}
+		if i >= attempt {
+			return wait
+		}
+		wait = time.Duration(float64(wait) * multiplier)
+	}
+}
+
+// RetryingModule is implemented by modules whose providers are retried on failure. Only providers returning
+// (T, error) could be retried.
+//
+//	func (m *PersistModule) AliceRetryPolicies() map[string]alice.RetryPolicy {
+//		return map[string]alice.RetryPolicy{
+//			"DB": {Attempts: 5, Backoff: 100 * time.Millisecond},
+//		}
+//	}
+//
+//	func (m *PersistModule) DB() (*sql.DB, error) {
+//		...
+//	}
+//
+// Each attempt is reported to the listeners by a ProviderCalling and a ProviderCalled event. A policy set by
+// WithRetryPolicy replaces the one declared by the module.
+type RetryingModule interface {
+	Module
+	// AliceRetryPolicies returns the retry policies of the providers, keyed by the provider method names.
+	AliceRetryPolicies() map[string]RetryPolicy
+}
+
+// WithRetryPolicy sets the retry policy of the provider of the instance with the specified name. The name is either
+// qualified, or unqualified if it is unambiguous. It is useful to tune the retries per environment without changing
+// the modules.
+func WithRetryPolicy(name string, policy RetryPolicy) Option {
+	return optionFunc(func(o *options) {
+		o.retryPolicies = append(o.retryPolicies, &namedRetryPolicy{
+			name:   name,
+			policy: policy,
+		})
+	})
+}
+
+// namedRetryPolicy is a retry policy of the instance with a name, set by WithRetryPolicy.
+type namedRetryPolicy struct {
+	name   string
+	policy RetryPolicy
+}
+
+// ProviderError is returned when a provider method returning (T, error) fails, after it is retried by its policy if
+// any.
+type ProviderError struct {
+	// Module is the name of the module providing the instance.
+	Module string
+	// Method is the name of the provider method.
+	Method string
+	// Instance is the qualified name of the instance.
+	Instance string
+	// Attempts is the number of calls made.
+	Attempts int
+	// Err is the error returned by the last call.
+	Err error
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("provider %s.%s of instance %s failed after %d attempt(s): %s",
+		e.Module, e.Method, e.Instance, e.Attempts, e.Err.Error())
+}
+
+// Unwrap returns the error returned by the provider.
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// returnsError checks if the provider method, with or without the receiver, returns (T, error).
+func returnsError(t reflect.Type) bool {
+	return t.NumOut() == 2 && t.Out(1) == _ErrorType
+}
+
+// applyRetryPolicies sets the retry policies of the instances if the module implements RetryingModule. It returns
+// error if a provider is not defined or doesn't return error.
+func applyRetryPolicies(m Module, instances []*instanceMethod) error {
+	retrying, ok := m.(RetryingModule)
+	if !ok || !declaresHook(m, _AliceRetryPoliciesMethodName) {
+		return nil
+	}
+
+	name := reflect.TypeOf(m).Elem().Name()
+	for method, policy := range retrying.AliceRetryPolicies() {
+		instance := findInstance(instances, method)
+		if instance == nil {
+			return fmt.Errorf("retried provider %s.%s is not defined", name, method)
+		}
+		if !instance.withError {
+			return fmt.Errorf("retried provider %s.%s doesn't return error", name, method)
+		}
+		policy := policy
+		instance.retryPolicy = &policy
+	}
+	return nil
+}
+
+// applyNamedRetryPolicies sets the retry policies passed by WithRetryPolicy. It returns error if no instance matches
+// the name, the name is ambiguous, or the provider doesn't return error.
+func applyNamedRetryPolicies(rms []*reflectedModule, policies []*namedRetryPolicy) error {
+	if len(policies) == 0 {
+		return nil
+	}
+	names := newNameIndex()
+	if err := names.add(rms...); err != nil {
+		return err
+	}
+	for _, p := range policies {
+		rm, instance, candidates := names.lookup(p.name)
+		if len(candidates) > 1 {
+			return fmt.Errorf("retry policy %s is ambiguous, use one of the qualified names %v", p.name, candidates)
+		}
+		if instance == nil {
+			return fmt.Errorf("retry policy %s does not match any instance", p.name)
+		}
+		if !instance.withError {
+			return fmt.Errorf("retry policy %s is set on provider %s.%s, which doesn't return error",
+				p.name, rm.name, instance.methodName)
+		}
+		policy := p.policy
+		instance.retryPolicy = &policy
+	}
+	return nil
+}
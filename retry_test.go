@@ -0,0 +1,177 @@
+package alice
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var errUnavailable = errors.New("broker unavailable")
+
+type FlakyModule struct {
+	BaseModule
+	Failures  int
+	Retryable func(err error) bool
+	calls     int
+}
+
+func (m *FlakyModule) AliceRetryPolicies() map[string]RetryPolicy {
+	return map[string]RetryPolicy{
+		"Broker": {Attempts: 3, Backoff: time.Millisecond, Retryable: m.Retryable},
+	}
+}
+
+func (m *FlakyModule) Broker() (D1, error) {
+	m.calls++
+	if m.calls <= m.Failures {
+		return nil, errUnavailable
+	}
+	return &D1Impl{}, nil
+}
+
+type FlakyServerModule struct {
+	BaseModule
+	*FlakyModule
+}
+
+func (m *FlakyServerModule) Address() string {
+	return ":8080"
+}
+
+type invalidRetryModule struct {
+	BaseModule
+}
+
+func (m *invalidRetryModule) AliceRetryPolicies() map[string]RetryPolicy {
+	return map[string]RetryPolicy{"D2": {Attempts: 3}}
+}
+
+func (m *invalidRetryModule) D2() D2 {
+	return &D2Impl{}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	m := &FlakyModule{Failures: 2}
+	var attempts []int
+	c, err := NewContainerWithOptions([]Module{m}, WithListener(func(e Event) {
+		if e.Kind == ProviderCalled {
+			attempts = append(attempts, e.Attempt)
+		}
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if expected := []int{1, 2, 3}; !reflect.DeepEqual(attempts, expected) {
+		t.Errorf("bad attempts reported: got %v, expected %v", attempts, expected)
+	}
+	if _, ok := c.InstanceByName("Broker").(*D1Impl); !ok {
+		t.Errorf("bad instance Broker: got %v", c.InstanceByName("Broker"))
+	}
+	if n := c.BuildReport().Providers[0].Attempts; n != 3 {
+		t.Errorf("bad attempts in report: got %d, expected 3", n)
+	}
+}
+
+func TestRetryPolicy_EmbeddedModule(t *testing.T) {
+	c, err := NewContainer(&FlakyServerModule{FlakyModule: &FlakyModule{Failures: 2}})
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if _, ok := c.InstanceByName("Broker").(*D1Impl); !ok {
+		t.Errorf("bad instance Broker: got %v", c.InstanceByName("Broker"))
+	}
+}
+
+func TestRetryPolicy_Exhausted(t *testing.T) {
+	_, err := NewContainer(&FlakyModule{Failures: 3})
+	if err == nil {
+		t.Fatal("expect error after NewContainer() on failing provider")
+	}
+	t.Log(err.Error())
+
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) {
+		t.Fatalf("bad error type after NewContainer(): got %T, expected *ProviderError", err)
+	}
+	expected := ProviderError{Module: "FlakyModule", Method: "Broker", Instance: "Broker", Attempts: 3,
+		Err: errUnavailable}
+	if *providerErr != expected {
+		t.Errorf("bad ProviderError: got %+v, expected %+v", *providerErr, expected)
+	}
+	if !errors.Is(err, errUnavailable) {
+		t.Errorf("bad unwrapped error: got %v, expected %v", errors.Unwrap(err), errUnavailable)
+	}
+}
+
+func TestRetryPolicy_NotRetryable(t *testing.T) {
+	m := &FlakyModule{Failures: 1, Retryable: func(err error) bool { return false }}
+	_, err := NewContainer(m)
+
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Attempts != 1 || m.calls != 1 {
+		t.Errorf("bad error after NewContainer() on non-retryable error: got %v after %d calls", err, m.calls)
+	}
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	m := &FlakyModule{Failures: 3}
+	_, err := NewContainerWithOptions([]Module{m}, WithRetryPolicy("Broker", RetryPolicy{Attempts: 4}))
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if m.calls != 4 {
+		t.Errorf("bad calls: got %d, expected 4", m.calls)
+	}
+}
+
+func TestWithRetryPolicy_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules []Module
+		opts    []Option
+	}{
+		{"unknown instance", []Module{&FlakyModule{}}, []Option{WithRetryPolicy("Unknown", RetryPolicy{Attempts: 2})}},
+		{"without error", []Module{&M1{}}, []Option{WithRetryPolicy("D1", RetryPolicy{Attempts: 2})}},
+		{"declared without error", []Module{&invalidRetryModule{}}, nil},
+	}
+	for _, test := range tests {
+		if _, err := NewContainerWithOptions(test.modules, test.opts...); err == nil {
+			t.Errorf("expect error after NewContainer() on %s", test.name)
+		} else {
+			t.Log(err.Error())
+		}
+	}
+}
+
+func TestWithOverride_ErrorProvider(t *testing.T) {
+	fake := &D1Impl{}
+	m := &FlakyModule{Failures: 3}
+	c, err := NewContainerWithOptions([]Module{m}, WithOverride("Broker", fake))
+	if err != nil {
+		t.Fatalf("unexpected error after NewContainer(): %s", err.Error())
+	}
+
+	if c.InstanceByName("Broker") != fake || m.calls != 0 {
+		t.Errorf("bad overridden instance Broker: got %v after %d calls", c.InstanceByName("Broker"), m.calls)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := &RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		800 * time.Millisecond, time.Second, time.Second}
+	for i, e := range expected {
+		if d := p.backoff(i + 1); d != e {
+			t.Errorf("bad backoff after attempt %d: got %s, expected %s", i+1, d, e)
+		}
+	}
+
+	p = &RetryPolicy{Backoff: 100 * time.Millisecond, Multiplier: 3}
+	if d := p.backoff(3); d != 900*time.Millisecond {
+		t.Errorf("bad backoff with multiplier 3: got %s, expected 900ms", d)
+	}
+}
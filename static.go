@@ -0,0 +1,70 @@
+package alice
+
+import (
+	"reflect"
+)
+
+// StaticContainer is a Container of instances which are already constructed, e.g. by the code generated by the alice
+// command. Instances are retrieved the same way as from a container created by CreateContainer, so the two could be
+// swapped.
+type StaticContainer struct {
+	c *container
+}
+
+// NewStaticContainer creates an empty StaticContainer.
+func NewStaticContainer() *StaticContainer {
+	return &StaticContainer{
+		c: &container{
+			instanceByName: make(map[string]interface{}),
+			instanceByType: make(map[reflect.Type][]interface{}),
+			qualifiedNames: make(map[string][]string),
+			aliases:        make(map[string]*aliasTarget),
+			opts:           defaultOptions(),
+		},
+	}
+}
+
+// Add adds an instance with the name and the type. The type is usually the return type of the provider method. The
+// name of an instance in a namespace is qualified, e.g. "payments.Client".
+func (s *StaticContainer) Add(name string, t reflect.Type, instance interface{}) {
+	s.c.addInstance(name, t, instance)
+}
+
+// AddFallback adds a fallback instance with the name and the type. It is found by type only if no instance added by Add
+// matches the type. Among the fallbacks, the first one added wins.
+func (s *StaticContainer) AddFallback(name string, t reflect.Type, instance interface{}) {
+	s.c.fallbacks = append(s.c.fallbacks, &fallbackInstance{name: name, tp: t})
+	s.c.addInstance(name, t, instance)
+}
+
+// AddAlias adds an alias of the instance with the name. Using a deprecated alias logs a warning by log.Printf.
+func (s *StaticContainer) AddAlias(alias string, name string, deprecated bool) {
+	s.c.addAlias(alias, name, deprecated)
+}
+
+// Instance returns an instance by type. It panics when no instance is found, or multiple instances are found for the
+// same type.
+func (s *StaticContainer) Instance(t reflect.Type) interface{} {
+	return s.c.Instance(t)
+}
+
+// InstanceByName returns an instance by name. It panics when no instance is found.
+func (s *StaticContainer) InstanceByName(name string) interface{} {
+	return s.c.InstanceByName(name)
+}
+
+// Overrides returns nil, because no override is applied to a StaticContainer.
+func (s *StaticContainer) Overrides() []Override {
+	return nil
+}
+
+// BuildReport returns an empty report, because a StaticContainer calls no provider.
+func (s *StaticContainer) BuildReport() *BuildReport {
+	return &BuildReport{Providers: []*ProviderTiming{}, Modules: []*ModuleTiming{}, CriticalModules: []string{},
+		CriticalPath: []string{}}
+}
+
+// Close does nothing, because a StaticContainer has no listener, and its instances are built by the generated code.
+func (s *StaticContainer) Close() error {
+	return nil
+}
@@ -0,0 +1,57 @@
+package alice
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStaticContainer(t *testing.T) {
+	var c Container = NewStaticContainer()
+	d1 := &D1Impl{}
+	d5 := &D5Impl{}
+	c.(*StaticContainer).Add("D1", reflect.TypeOf((*D1)(nil)).Elem(), d1)
+	c.(*StaticContainer).Add("D5", reflect.TypeOf(d5), d5)
+
+	if instance := c.InstanceByName("D1"); instance != d1 {
+		t.Errorf("bad instance from InstanceByName(): got %v, expected %v", instance, d1)
+	}
+	if instance := c.Instance(reflect.TypeOf((*D1)(nil)).Elem()); instance != d1 {
+		t.Errorf("bad instance from Instance(): got %v, expected %v", instance, d1)
+	}
+	if instance := c.Instance(reflect.TypeOf((*D5)(nil)).Elem()); instance != d5 {
+		t.Errorf("bad instance from Instance() of assignable type: got %v, expected %v", instance, d5)
+	}
+	if overrides := c.Overrides(); overrides != nil {
+		t.Errorf("bad overrides from StaticContainer: got %v, expected nil", overrides)
+	}
+}
+
+func TestStaticContainer_AddFallback(t *testing.T) {
+	c := NewStaticContainer()
+	fallback := &decoratedD1{}
+	d1 := &D1Impl{}
+	c.AddFallback("StockD1", reflect.TypeOf((*D1)(nil)).Elem(), fallback)
+
+	if instance := c.Instance(reflect.TypeOf((*D1)(nil)).Elem()); instance != fallback {
+		t.Errorf("bad instance from Instance() of fallback: got %v, expected %v", instance, fallback)
+	}
+	c.Add("D1", reflect.TypeOf(d1), d1)
+	if instance := c.Instance(reflect.TypeOf((*D1)(nil)).Elem()); instance != d1 {
+		t.Errorf("bad instance from Instance() with fallback: got %v, expected %v", instance, d1)
+	}
+	if instance := c.InstanceByName("StockD1"); instance != fallback {
+		t.Errorf("bad instance from InstanceByName() of fallback: got %v, expected %v", instance, fallback)
+	}
+}
+
+func TestStaticContainer_PanicOnNameNotFound(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected panic for InstanceByName() on name not found")
+		} else {
+			t.Log(r)
+		}
+	}()
+
+	NewStaticContainer().InstanceByName("D1")
+}
@@ -0,0 +1,9 @@
+package alice
+
+// Validate checks the modules the same way as building a container, without calling any provider method. It returns
+// error if any of the module is invalid, a dependency is not satisfied, the instance of a named dependency is not
+// assignable to the field, or there are cyclic dependencies. It is useful to catch wiring errors in CI.
+func Validate(modules ...Module) error {
+	_, err := plan(modules, defaultOptions())
+	return err
+}
@@ -0,0 +1,35 @@
+package alice
+
+import (
+	"testing"
+)
+
+type validateTestModule struct {
+	BaseModule
+}
+
+func (m *validateTestModule) D1() D1 {
+	panic("provider should not be called by Validate()")
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(&validateTestModule{}, &M4{}); err != nil {
+		t.Errorf("unexpected error after Validate(): %s", err.Error())
+	}
+}
+
+func TestValidate_Error(t *testing.T) {
+	err := Validate(&M1{}, &M2{}, &M3{}, &M6{})
+	if err == nil {
+		t.Error("expect error after Validate() on modules with cyclic dependencies")
+	}
+	t.Log(err.Error())
+}
+
+func TestValidate_NameTypeNotAssignable(t *testing.T) {
+	err := Validate(&M1{}, &MismatchedNameTypeModule{})
+	if err == nil {
+		t.Error("expect error after Validate() on named dependency type not assignable")
+	}
+	t.Log(err.Error())
+}